@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"user-service/config"
+	"user-service/internal/adapter/breach"
+	"user-service/internal/adapter/repository"
+	"user-service/internal/adapter/session"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/service"
+	"user-service/utils/i18n"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/spf13/cobra"
+)
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "manage user accounts",
+}
+
+var (
+	userCreateName     string
+	userCreatePassword string
+	userCreateRole     string
+	userCreateVerified bool
+	userCreateLanguage string
+)
+
+var userCreateCmd = &cobra.Command{
+	Use:   "create <email>",
+	Short: "create a user, printing the generated password when none is given",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if userCreateLanguage != "" && !i18n.IsSupported(userCreateLanguage) {
+			return fmt.Errorf("language must be one of %v", i18n.Supported)
+		}
+
+		return withAdminService(cmd, func(ctx context.Context, admin service.AdminServiceInterface) error {
+			user, password, err := admin.CreateUser(ctx, entity.UserEntity{
+				Name:       userCreateName,
+				Email:      args[0],
+				Password:   userCreatePassword,
+				RoleName:   userCreateRole,
+				IsVerified: userCreateVerified,
+				Language:   userCreateLanguage,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("created user %d %s (%s)\n", user.ID, user.Email, user.RoleName)
+			if userCreatePassword == "" {
+				fmt.Printf("password: %s\n", password)
+			}
+			return nil
+		})
+	},
+}
+
+var userVerifyCmd = &cobra.Command{
+	Use:   "verify <email>",
+	Short: "mark a user as verified without the email link",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, admin service.AdminServiceInterface) error {
+			user, err := admin.VerifyUser(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("user %d %s is verified\n", user.ID, user.Email)
+			return nil
+		})
+	},
+}
+
+var userResetPasswordValue string
+
+var userResetPasswordCmd = &cobra.Command{
+	Use:   "reset-password <email>",
+	Short: "set a new password and sign the user out everywhere",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, admin service.AdminServiceInterface) error {
+			password, err := admin.ResetPassword(ctx, args[0], userResetPasswordValue)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("password of %s reset\n", args[0])
+			if userResetPasswordValue == "" {
+				fmt.Printf("password: %s\n", password)
+			}
+			return nil
+		})
+	},
+}
+
+var userSuspendLift bool
+
+var userSuspendCmd = &cobra.Command{
+	Use:   "suspend <email>",
+	Short: "suspend a user and sign them out everywhere, or lift it with --lift",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, admin service.AdminServiceInterface) error {
+			user, err := admin.SetSuspended(ctx, args[0], !userSuspendLift)
+			if err != nil {
+				return err
+			}
+
+			if user.IsSuspended {
+				fmt.Printf("user %d %s is suspended\n", user.ID, user.Email)
+			} else {
+				fmt.Printf("user %d %s is no longer suspended\n", user.ID, user.Email)
+			}
+			return nil
+		})
+	},
+}
+
+var roleCmd = &cobra.Command{
+	Use:   "role",
+	Short: "manage user roles",
+}
+
+var roleAssignCmd = &cobra.Command{
+	Use:   "assign <email> <role>",
+	Short: "replace the role of a user and sign them out",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, admin service.AdminServiceInterface) error {
+			user, err := admin.AssignRole(ctx, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("user %d %s is now %s\n", user.ID, user.Email, user.RoleName)
+			return nil
+		})
+	},
+}
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "manage sessions",
+}
+
+var sessionRevokeUser string
+
+var sessionRevokeCmd = &cobra.Command{
+	Use:   "revoke [token]",
+	Short: "revoke one session by access token, or every session of a user with --user",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if (len(args) == 1) == (sessionRevokeUser != "") {
+			return fmt.Errorf("give either a token or --user")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, admin service.AdminServiceInterface) error {
+			if sessionRevokeUser != "" {
+				revoked, err := admin.RevokeUserSessions(ctx, sessionRevokeUser)
+				if err != nil {
+					return err
+				}
+
+				fmt.Printf("%d sessions of %s revoked\n", revoked, sessionRevokeUser)
+				return nil
+			}
+
+			if err := admin.RevokeSession(ctx, args[0]); err != nil {
+				return err
+			}
+			fmt.Println("session revoked")
+			return nil
+		})
+	},
+}
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "access token tools",
+}
+
+var tokenMintCmd = &cobra.Command{
+	Use:   "mint <email>",
+	Short: "issue an access token for a user, for debugging; disabled in production",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, admin service.AdminServiceInterface) error {
+			token, err := admin.MintToken(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(token)
+			return nil
+		})
+	},
+}
+
+// withAdminService loads the configuration, connects to the database and
+// the session store and runs fn with the admin service.
+func withAdminService(cmd *cobra.Command, fn func(ctx context.Context, admin service.AdminServiceInterface) error) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	log := cfg.NewLogger()
+
+	db, err := cfg.ConnectionPostgres(log)
+	if err != nil {
+		return err
+	}
+
+	var redisClient redis.UniversalClient
+	if cfg.Session.Driver == session.DriverRedis {
+		redisClient, err = cfg.NewRedisClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer redisClient.Close()
+	}
+
+	sessionStore, err := session.NewSessionStore(cfg, log, redisClient, db.DB)
+	if err != nil {
+		return err
+	}
+
+	passwords, err := newPasswordService(cfg, log)
+	if err != nil {
+		return err
+	}
+
+	userRepo := repository.NewUserRepository(db.DB, log)
+	admin := service.NewAdminService(log, cfg, userRepo, service.NewJwtService(cfg), sessionStore, passwords)
+	return fn(ctx, admin)
+}
+
+// newPasswordService returns the password policy of cfg, with the breached
+// password check it configures.
+func newPasswordService(cfg *config.Config, log *slog.Logger) (service.PasswordServiceInterface, error) {
+	breachProvider, err := breach.NewProvider(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewPasswordService(log, cfg, breachProvider)
+}
+
+func init() {
+	userCreateCmd.Flags().StringVar(&userCreateName, "name", "", "full name")
+	userCreateCmd.Flags().StringVar(&userCreatePassword, "password", "", "password; generated and printed when empty")
+	userCreateCmd.Flags().StringVar(&userCreateRole, "role", entity.RoleCustomer, "role name")
+	userCreateCmd.Flags().BoolVar(&userCreateVerified, "verified", true, "create the user already verified")
+	userCreateCmd.Flags().StringVar(&userCreateLanguage, "language", "", "preferred language, en or id (default from config)")
+	_ = userCreateCmd.MarkFlagRequired("name")
+	userResetPasswordCmd.Flags().StringVar(&userResetPasswordValue, "password", "", "new password; generated and printed when empty")
+	userSuspendCmd.Flags().BoolVar(&userSuspendLift, "lift", false, "lift the suspension instead")
+	sessionRevokeCmd.Flags().StringVar(&sessionRevokeUser, "user", "", "email of the user whose sessions to revoke")
+
+	userCmd.AddCommand(userCreateCmd, userVerifyCmd, userResetPasswordCmd, userSuspendCmd)
+	roleCmd.AddCommand(roleAssignCmd)
+	sessionCmd.AddCommand(sessionRevokeCmd)
+	tokenCmd.AddCommand(tokenMintCmd)
+	rootCmd.AddCommand(userCmd, roleCmd, sessionCmd, tokenCmd)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "inspect configuration",
+}
+
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "validate the configuration and print the effective values",
+	Long:  "Load the configuration the same way start does, print every key with its effective value (secrets redacted) and exit non-zero when validation fails.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := readConfig()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tENV\tVALUE")
+		for _, s := range cfg.Settings() {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Path, s.Env, s.Value)
+		}
+		w.Flush()
+
+		if err := cfg.Validate(); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+
+		fmt.Println("\nconfiguration OK")
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configCheckCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"user-service/database/migrations"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "run the embedded database migrations",
+	Long:  "Run the SQL migrations embedded in the binary against the configured database. Concurrent runs wait on a postgres advisory lock instead of racing.",
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "apply every pending migration",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(cmd, func(m *migrations.Migrator) error {
+			return m.Up()
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down [steps]",
+	Short: "roll back the last migration, or the last steps migrations",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps := 1
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("steps must be a number: %w", err)
+			}
+			steps = n
+		}
+
+		return withMigrator(cmd, func(m *migrations.Migrator) error {
+			return m.Down(steps)
+		})
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "print the current and latest migration versions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(cmd, func(m *migrations.Migrator) error {
+			status, err := m.Status()
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case !status.Applied:
+				fmt.Printf("no migration applied, latest is %d\n", status.Latest)
+			case status.Dirty:
+				fmt.Printf("version %d is dirty, fix the schema and run migrate force\n", status.Version)
+			case status.Version < status.Latest:
+				fmt.Printf("version %d, %d is available\n", status.Version, status.Latest)
+			default:
+				fmt.Printf("version %d, up to date\n", status.Version)
+			}
+			return nil
+		})
+	},
+}
+
+var migrateGotoCmd = &cobra.Command{
+	Use:   "goto <version>",
+	Short: "migrate up or down to a version",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil {
+			return fmt.Errorf("version must be a number: %w", err)
+		}
+
+		return withMigrator(cmd, func(m *migrations.Migrator) error {
+			return m.Goto(uint(version))
+		})
+	},
+}
+
+var migrateForceCmd = &cobra.Command{
+	Use:   "force <version>",
+	Short: "mark a version as applied and clear the dirty flag, without running it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("version must be a number: %w", err)
+		}
+
+		return withMigrator(cmd, func(m *migrations.Migrator) error {
+			return m.Force(version)
+		})
+	},
+}
+
+// withMigrator loads the configuration, opens a migrator and runs fn with it.
+func withMigrator(cmd *cobra.Command, fn func(m *migrations.Migrator) error) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	m, err := migrations.New(cfg, cfg.NewLogger())
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return fn(m)
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd, migrateGotoCmd, migrateForceCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
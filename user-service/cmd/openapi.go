@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"user-service/internal/adapter"
+	"user-service/internal/adapter/handler"
+	"user-service/internal/adapter/handler/openapi"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/cobra"
+)
+
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "inspect the openapi spec",
+}
+
+var openapiCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "fail when the routes and the openapi spec differ",
+	Long:  "Register the HTTP routes without connecting to anything and compare them, and the request and response structs, with the embedded openapi spec. Exits non-zero on any difference, so it can run in CI.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		doc, err := openapi.Load()
+		if err != nil {
+			return err
+		}
+
+		// only the route table is inspected, no handler or middleware runs
+		e := echo.New()
+		handler.NewUserHandler(e, nil, adapter.NewMiddlewareAdapter(nil, nil, nil))
+		openapi.Register(e, handler.APIPrefix, true)
+
+		if err := openapi.Check(doc, e.Routes(), handler.APIPrefix); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+
+		fmt.Fprintln(os.Stdout, "openapi spec OK")
+		return nil
+	},
+}
+
+var openapiPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "print the embedded openapi spec",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := os.Stdout.Write(openapi.Spec)
+		return err
+	},
+}
+
+func init() {
+	openapiCmd.AddCommand(openapiCheckCmd)
+	openapiCmd.AddCommand(openapiPrintCmd)
+	rootCmd.AddCommand(openapiCmd)
+}
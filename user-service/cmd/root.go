@@ -3,15 +3,17 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"user-service/config"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var cfgFile string
 var rootCmd = &cobra.Command{
 	Use:   "sayur-api",
 	Short: "tulis api for sayur",
+	// errors are printed once by cobra.CheckErr in Execute
+	SilenceErrors: true,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Run(startCmd, nil)
 	},
@@ -22,21 +24,29 @@ func Execute() {
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is .env)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file, .env, .yaml or .json (default is .env)")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
-func initConfig() {
-	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
-	} else {
-		viper.SetConfigFile(`.env`)
+// loadConfig loads the configuration and validates it, returning every
+// problem found at once.
+func loadConfig() (*config.Config, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
 	}
 
-	viper.AutomaticEnv()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
 
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+func readConfig() (*config.Config, error) {
+	file := config.ResolveFile(cfgFile)
+	if file != "" {
+		fmt.Fprintln(os.Stderr, "Using config file:", file)
 	}
+
+	return config.Load(file)
 }
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"user-service/config"
+	"user-service/database/seeds"
+	"user-service/internal/core/domain/entity"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	seedSet   string
+	seedForce bool
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "seed the database",
+	Long: `Apply a seed set. "base" creates the roles and the super admin and is safe in
+every environment. "demo" adds fake customers for local development and is
+refused in production. Every seed runs once; later runs skip it unless --force.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+
+		sets := seeds.Sets(seeds.Options{
+			Admin: seeds.AdminOptions{
+				Name:     cfg.Seed.AdminName,
+				Email:    cfg.Seed.AdminEmail,
+				Password: cfg.Seed.AdminPassword,
+			},
+			Hasher:        cfg.NewPasswordHasher(),
+			DemoCustomers: cfg.Seed.DemoCustomers,
+			Out:           os.Stdout,
+		})
+		set, ok := sets[seedSet]
+		if !ok {
+			names := make([]string, 0, len(sets))
+			for name := range sets {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown seed set %q, use one of %s", seedSet, strings.Join(names, ", "))
+		}
+		if seedSet == seeds.SetDemo && cfg.App.AppEnv == config.EnvProduction {
+			return fmt.Errorf("the demo seed set is not allowed in production")
+		}
+
+		log := cfg.NewLogger()
+		if cfg.Seed.AdminPassword != "" {
+			passwords, err := newPasswordService(cfg, log)
+			if err != nil {
+				return err
+			}
+			admin := entity.UserEntity{Name: cfg.Seed.AdminName, Email: cfg.Seed.AdminEmail}
+			if err := passwords.Validate(context.Background(), cfg.Seed.AdminPassword, admin); err != nil {
+				return fmt.Errorf("seed.admin_password: %w", err)
+			}
+		}
+
+		db, err := cfg.ConnectionPostgres(log)
+		if err != nil {
+			return err
+		}
+
+		return seeds.Run(db.DB, log, set, seedForce)
+	},
+}
+
+func init() {
+	seedCmd.Flags().StringVar(&seedSet, "set", seeds.SetBase, "seed set to apply: base or demo")
+	seedCmd.Flags().BoolVar(&seedForce, "force", false, "run seeds again even when already applied")
+	rootCmd.AddCommand(seedCmd)
+}
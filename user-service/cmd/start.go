@@ -6,12 +6,25 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var autoMigrate bool
+
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "start",
 	Long:  "start",
 	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		cobra.CheckErr(err)
+		if autoMigrate {
+			cfg.Psql.AutoMigrate = true
+		}
+
 		// Start the user service
-		app.RunServer()
+		cobra.CheckErr(app.RunServer(cfg))
 	},
 }
+
+func init() {
+	startCmd.Flags().BoolVar(&autoMigrate, "migrate", false, "apply pending migrations before starting (same as DATABASE_AUTO_MIGRATE=true)")
+	rootCmd.AddCommand(startCmd)
+}
@@ -1,6 +1,6 @@
 package config
 
-import "github.com/spf13/viper"
+import "time"
 
 type App struct {
 	AppPort string `json:"app_port"`
@@ -9,7 +9,9 @@ type App struct {
 	JwtSecretKey string `json:"jwt_secret_key"`
 	JwtIssuer    string `json:"jwt_issuer"`
 
-	UrlForgotPassword string `json:"url_forgot_password"`
+	// DefaultLanguage is used when Accept-Language matches no supported
+	// language and for users without a stored preference.
+	DefaultLanguage string `json:"default_language"`
 }
 
 type PsqlDB struct {
@@ -20,6 +22,14 @@ type PsqlDB struct {
 	DBName    string `json:"db_name"`
 	DBMaxOpen int    `json:"db_max_open"`
 	DBMaxIdle int    `json:"db_max_idle"`
+
+	// AutoMigrate applies pending migrations when the server starts.
+	AutoMigrate bool `json:"auto_migrate"`
+	// QueryTimeout bounds every single query; zero leaves queries bounded
+	// by the request context only.
+	QueryTimeout time.Duration `json:"query_timeout"`
+	// SlowQueryThreshold is the duration above which queries are logged.
+	SlowQueryThreshold time.Duration `json:"slow_query_threshold"`
 }
 
 type RabbitMQ struct {
@@ -29,35 +39,172 @@ type RabbitMQ struct {
 	Password string `json:"password"`
 }
 
+type Redis struct {
+	Mode         string        `json:"mode"`
+	Addrs        []string      `json:"addrs"`
+	MasterName   string        `json:"master_name"`
+	Password     string        `json:"password"`
+	DB           int           `json:"db"`
+	TLS          bool          `json:"tls"`
+	PoolSize     int           `json:"pool_size"`
+	DialTimeout  time.Duration `json:"dial_timeout"`
+	ReadTimeout  time.Duration `json:"read_timeout"`
+	WriteTimeout time.Duration `json:"write_timeout"`
+}
+
+type Session struct {
+	Driver string        `json:"driver"`
+	TTL    time.Duration `json:"ttl"`
+}
+
+type Message struct {
+	Broker string `json:"broker"`
+}
+
+type Kafka struct {
+	Brokers     []string `json:"brokers"`
+	TopicPrefix string   `json:"topic_prefix"`
+}
+
+type Log struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+type Health struct {
+	CheckTimeout time.Duration `json:"check_timeout"`
+	CacheTTL     time.Duration `json:"cache_ttl"`
+	DrainDelay   time.Duration `json:"drain_delay"`
+}
+
+type Tracing struct {
+	Exporter    string  `json:"exporter"`
+	Endpoint    string  `json:"endpoint"`
+	Protocol    string  `json:"protocol"`
+	Insecure    bool    `json:"insecure"`
+	SampleRatio float64 `json:"sample_ratio"`
+	ServiceName string  `json:"service_name"`
+}
+
+// Secrets sets where secrets come from and how often they are re-read. The JWT
+// key and the database, Redis and RabbitMQ passwords are applied live; other
+// secrets take effect on the next start.
+type Secrets struct {
+	Provider        string        `json:"provider"`
+	RefreshInterval time.Duration `json:"refresh_interval"`
+}
+
+type Vault struct {
+	Address string `json:"address"`
+	Token   string `json:"token"`
+	Mount   string `json:"mount"`
+	Path    string `json:"path"`
+}
+
+// Seed configures the seed command. An empty AdminPassword makes it generate
+// one and print it once.
+type Seed struct {
+	AdminName     string `json:"admin_name"`
+	AdminEmail    string `json:"admin_email"`
+	AdminPassword string `json:"admin_password"`
+	DemoCustomers int    `json:"demo_customers"`
+}
+
+// Token sets how long emailed verification and reset password tokens stay
+// valid.
+type Token struct {
+	EmailVerificationTTL time.Duration `json:"email_verification_ttl"`
+	ResetPasswordTTL     time.Duration `json:"reset_password_ttl"`
+	// ResendCooldown is the minimum time between two verification emails,
+	// or two reset password emails, requested for the same address.
+	ResendCooldown time.Duration `json:"resend_cooldown"`
+}
+
+// Links holds the URL templates of the links mailed to users, by link type
+// and client. {token} is replaced by the token and {lang} by the user
+// language. Android and iOS fall back to the web template when empty.
+type Links struct {
+	VerifyEmail   ClientLinks `json:"verify_email"`
+	ResetPassword ClientLinks `json:"reset_password"`
+}
+
+type ClientLinks struct {
+	Web     string `json:"web"`
+	Android string `json:"android"`
+	IOS     string `json:"ios"`
+}
+
+// Scheduler configures the background cleanup jobs. Schedules use cron
+// syntax, including descriptors like @hourly and @every 15m. A zero day
+// count turns the matching unverified user job off.
+type Scheduler struct {
+	Enabled                 bool          `json:"enabled"`
+	TokenCleanupSchedule    string        `json:"token_cleanup_schedule"`
+	SessionCleanupSchedule  string        `json:"session_cleanup_schedule"`
+	UnverifiedUsersSchedule string        `json:"unverified_users_schedule"`
+	TokenRetention          time.Duration `json:"token_retention"`
+	UnverifiedRemindDays    int           `json:"unverified_remind_days"`
+	UnverifiedRemindRetry   time.Duration `json:"unverified_remind_retry"`
+	UnverifiedDeleteDays    int           `json:"unverified_delete_days"`
+}
+
+// Password is the policy every new password must meet. BlocklistFile adds
+// passwords, one per line, to the built in list of common ones.
+// BreachProvider picks where passwords are checked against known breaches:
+// none, local (BreachListFile) or hibp (BreachAPIURL). Passwords are hashed
+// with Argon2id; Argon2Memory is in KiB.
+type Password struct {
+	MinLength      int           `json:"min_length"`
+	MaxLength      int           `json:"max_length"`
+	RequireUpper   bool          `json:"require_upper"`
+	RequireLower   bool          `json:"require_lower"`
+	RequireDigit   bool          `json:"require_digit"`
+	RequireSymbol  bool          `json:"require_symbol"`
+	RejectPersonal bool          `json:"reject_personal"`
+	BlocklistFile  string        `json:"blocklist_file"`
+	BreachProvider string        `json:"breach_provider"`
+	BreachListFile string        `json:"breach_list_file"`
+	BreachAPIURL   string        `json:"breach_api_url"`
+	BreachTimeout  time.Duration `json:"breach_timeout"`
+
+	Argon2Memory      int `json:"argon2_memory"`
+	Argon2Time        int `json:"argon2_time"`
+	Argon2Parallelism int `json:"argon2_parallelism"`
+}
+
+// GRPC configures the internal gRPC server. Setting ClientCAFile turns on
+// mutual TLS; AuthToken, when set, must be sent by callers as a bearer token.
+type GRPC struct {
+	Enabled         bool          `json:"enabled"`
+	Port            int           `json:"port"`
+	CertFile        string        `json:"cert_file"`
+	KeyFile         string        `json:"key_file"`
+	ClientCAFile    string        `json:"client_ca_file"`
+	AuthToken       string        `json:"auth_token"`
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+}
+
 type Config struct {
-	App      App      `json:"app"`
-	Psql     PsqlDB   `json:"db"`
-	RabbitMQ RabbitMQ `json:"rabbitmq"`
-}
-
-func NewConfig() *Config {
-	return &Config{
-		App: App{
-			AppPort:           viper.GetString("APP_PORT"),
-			AppEnv:            viper.GetString("APP_ENV"),
-			JwtSecretKey:      viper.GetString("JWT_SECRET_KEY"),
-			JwtIssuer:         viper.GetString("JWT_ISSUER"),
-			UrlForgotPassword: viper.GetString("URL_FORGOT_PASSWORD"),
-		},
-		Psql: PsqlDB{
-			Host:      viper.GetString("DATABASE_HOST"),
-			Port:      viper.GetInt("DATABASE_PORT"),
-			User:      viper.GetString("DATABASE_USER"),
-			Password:  viper.GetString("DATABASE_PASSWORD"),
-			DBName:    viper.GetString("DATABASE_NAME"),
-			DBMaxOpen: viper.GetInt("DATABASE_MAX_OPEN"),
-			DBMaxIdle: viper.GetInt("DATABASE_MAX_IDLE"),
-		},
-		RabbitMQ: RabbitMQ{
-			Host:     viper.GetString("RABBITMQ_HOST"),
-			Port:     viper.GetInt("RABBITMQ_PORT"),
-			User:     viper.GetString("RABBITMQ_USER"),
-			Password: viper.GetString("RABBITMQ_PASSWORD"),
-		},
-	}
+	App       App       `json:"app"`
+	Psql      PsqlDB    `json:"db"`
+	RabbitMQ  RabbitMQ  `json:"rabbitmq"`
+	Redis     Redis     `json:"redis"`
+	Session   Session   `json:"session"`
+	Message   Message   `json:"message"`
+	Kafka     Kafka     `json:"kafka"`
+	Log       Log       `json:"log"`
+	Health    Health    `json:"health"`
+	Tracing   Tracing   `json:"tracing"`
+	Secrets   Secrets   `json:"secrets"`
+	Vault     Vault     `json:"vault"`
+	GRPC      GRPC      `json:"grpc"`
+	Seed      Seed      `json:"seed"`
+	Token     Token     `json:"token"`
+	Links     Links     `json:"links"`
+	Scheduler Scheduler `json:"scheduler"`
+	Password  Password  `json:"password"`
+
+	// SecretValues holds the values last fetched from the secret provider,
+	// keyed like the provider returns them.
+	SecretValues map[string]string `json:"-"`
 }
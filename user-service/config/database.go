@@ -1,43 +1,163 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"user-service/database/seeds"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
 
-	"github.com/rs/zerolog/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 )
 
+// Postgres is the shared database handle. Pooled connections authenticate
+// with the current password, so RotatePassword applies to new connections
+// without reopening the pool.
 type Postgres struct {
 	DB *gorm.DB
+
+	connConfig *pgx.ConnConfig
+	password   atomic.Pointer[string]
 }
 
-func (cfg Config) ConnectionPostgres() (*Postgres, error) {
-	dbConnString := fmt.Sprintf("postgres://%s:%s@%s:%d/%s",
-		cfg.Psql.User,
-		cfg.Psql.Password,
-		cfg.Psql.Host,
-		cfg.Psql.Port,
-		cfg.Psql.DBName,
-	)
+// PostgresURL returns the postgres:// connection URL of the database.
+func (cfg Config) PostgresURL() string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.Psql.User, cfg.Psql.Password),
+		Host:   net.JoinHostPort(cfg.Psql.Host, strconv.Itoa(cfg.Psql.Port)),
+		Path:   cfg.Psql.DBName,
+	}
+	return u.String()
+}
 
-	db, err := gorm.Open(postgres.Open(dbConnString), &gorm.Config{})
+func (cfg Config) ConnectionPostgres(log *slog.Logger) (*Postgres, error) {
+	connConfig, err := pgx.ParseConfig(cfg.PostgresURL())
 	if err != nil {
-		log.Error().Err(err).Msg("[ConnectionPostgres-1] Failed to connect database " + cfg.Psql.Host)
-		return nil, err
+		return nil, fmt.Errorf("parse database config: %w", err)
 	}
+	p := &Postgres{connConfig: connConfig}
+	p.password.Store(&cfg.Psql.Password)
+
+	sqlDB := stdlib.OpenDB(*connConfig, stdlib.OptionBeforeConnect(func(ctx context.Context, c *pgx.ConnConfig) error {
+		c.Password = *p.password.Load()
+		return nil
+	}))
 
-	sqlDB, err := db.DB()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: gormlogger.NewSlogLogger(log.With("component", "gorm"), gormlogger.Config{
+			LogLevel:                  gormlogger.Warn,
+			SlowThreshold:             cfg.Psql.SlowQueryThreshold,
+			IgnoreRecordNotFoundError: true,
+			ParameterizedQueries:      true,
+		}),
+		// report constraint violations as gorm.ErrDuplicatedKey and friends
+		TranslateError: true,
+	})
 	if err != nil {
-		log.Error().Err(err).Msg("[ConnectionPostgres-2] Failed to get database connection ")
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to connect database %s: %w", cfg.Psql.Host, err)
 	}
 
-	seeds.SeedRole(db)
-	seeds.SeedAdmin(db)
+	if err := db.Use(queryTimeout(cfg.Psql.QueryTimeout)); err != nil {
+		return nil, fmt.Errorf("register query timeout: %w", err)
+	}
 
 	sqlDB.SetMaxOpenConns(cfg.Psql.DBMaxOpen)
 	sqlDB.SetMaxIdleConns(cfg.Psql.DBMaxIdle)
 
-	return &Postgres{DB: db}, nil
+	p.DB = db
+	return p, nil
+}
+
+// RotatePassword checks password with a fresh connection and, when it is
+// accepted, has every new pooled connection use it. Open connections stay
+// as they are; the old password is kept when the new one is refused.
+func (p *Postgres) RotatePassword(ctx context.Context, password string) error {
+	connConfig := p.connConfig.Copy()
+	connConfig.Password = password
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return fmt.Errorf("connect with the rotated database password: %w", err)
+	}
+	conn.Close(ctx)
+
+	p.password.Store(&password)
+	return nil
+}
+
+// Ping checks that the database accepts connections.
+func (p *Postgres) Ping(ctx context.Context) error {
+	sqlDB, err := p.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+const queryTimeoutKey = "query_timeout:parent"
+
+type boundedQuery struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// queryTimeout is a GORM plugin bounding every query to its duration, on top
+// of the deadline of the statement context. Row queries are left alone: their
+// rows are read after the callbacks return.
+type queryTimeout time.Duration
+
+func (queryTimeout) Name() string {
+	return "query_timeout"
+}
+
+func (t queryTimeout) Initialize(db *gorm.DB) error {
+	if t <= 0 {
+		return nil
+	}
+
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("query_timeout:before_create", t.before),
+		cb.Create().After("gorm:create").Register("query_timeout:after_create", t.after),
+		cb.Query().Before("gorm:query").Register("query_timeout:before_query", t.before),
+		cb.Query().After("gorm:query").Register("query_timeout:after_query", t.after),
+		cb.Update().Before("gorm:update").Register("query_timeout:before_update", t.before),
+		cb.Update().After("gorm:update").Register("query_timeout:after_update", t.after),
+		cb.Delete().Before("gorm:delete").Register("query_timeout:before_delete", t.before),
+		cb.Delete().After("gorm:delete").Register("query_timeout:after_delete", t.after),
+		cb.Raw().Before("gorm:raw").Register("query_timeout:before_raw", t.before),
+		cb.Raw().After("gorm:raw").Register("query_timeout:after_raw", t.after),
+	)
+}
+
+func (t queryTimeout) before(db *gorm.DB) {
+	parent := db.Statement.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, time.Duration(t))
+	db.Statement.Context = ctx
+	db.InstanceSet(queryTimeoutKey, boundedQuery{parent: parent, cancel: cancel})
+}
+
+// after cancels the query context and puts the parent back, as statements
+// like associations run further queries on the same instance.
+func (queryTimeout) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(queryTimeoutKey)
+	if !ok {
+		return
+	}
+	if query, ok := value.(boundedQuery); ok {
+		query.cancel()
+		db.Statement.Context = query.parent
+	}
 }
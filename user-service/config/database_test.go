@@ -0,0 +1,97 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// sleepingConnPool stands in for a database running pg_sleep: every statement
+// returns only once its context is done.
+type sleepingConnPool struct{}
+
+func (sleepingConnPool) wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(10 * time.Second):
+		return errors.New("statement ran to completion")
+	}
+}
+
+func (p sleepingConnPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, p.wait(ctx)
+}
+
+func (p sleepingConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, p.wait(ctx)
+}
+
+func (p sleepingConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, p.wait(ctx)
+}
+
+func (p sleepingConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	p.wait(ctx)
+	return nil
+}
+
+func TestQueryTimeoutCutsOffSlowStatements(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sleepingConnPool{}}), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.Use(queryTimeout(50 * time.Millisecond)); err != nil {
+		t.Fatalf("use: %v", err)
+	}
+
+	type user struct{ ID int64 }
+	statements := map[string]func(db *gorm.DB) error{
+		"raw":    func(db *gorm.DB) error { return db.Exec("SELECT pg_sleep(10)").Error },
+		"query":  func(db *gorm.DB) error { return db.First(&user{}).Error },
+		"create": func(db *gorm.DB) error { return db.Create(&user{}).Error },
+	}
+
+	for name, run := range statements {
+		t.Run(name, func(t *testing.T) {
+			// the request itself has no deadline; only the plugin bounds the statement
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			start := time.Now()
+			err := run(db.WithContext(ctx))
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("returned after %s, want the 50ms query timeout", elapsed)
+			}
+			if ctx.Err() != nil {
+				t.Error("the request context was cancelled along with the statement")
+			}
+		})
+	}
+}
+
+func TestQueryTimeoutDisabled(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sleepingConnPool{}}), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.Use(queryTimeout(0)); err != nil {
+		t.Fatalf("use: %v", err)
+	}
+
+	// without a timeout the request deadline is all that stops the statement
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if err := db.WithContext(ctx).Exec("SELECT pg_sleep(10)").Error; !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
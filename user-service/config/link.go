@@ -0,0 +1,19 @@
+package config
+
+import "user-service/utils/link"
+
+// NewLinkBuilder returns the builder of the links mailed to users.
+func (cfg Config) NewLinkBuilder() *link.Builder {
+	return link.NewBuilder(link.Templates{
+		link.VerifyEmail:   cfg.Links.VerifyEmail.templates(),
+		link.ResetPassword: cfg.Links.ResetPassword.templates(),
+	})
+}
+
+func (l ClientLinks) templates() map[string]string {
+	return map[string]string{
+		link.Web:     l.Web,
+		link.Android: l.Android,
+		link.IOS:     l.IOS,
+	}
+}
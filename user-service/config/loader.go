@@ -0,0 +1,359 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+	"github.com/subosito/gotenv"
+)
+
+const DefaultConfigFile = ".env"
+
+// setting describes one configuration key. Path is the dotted key used in
+// YAML/JSON files (it follows the json tags of Config), Env lists the
+// environment variables that can set it, the first one being canonical.
+type setting struct {
+	Path    string
+	Env     []string
+	Default interface{}
+	Secret  bool
+}
+
+var settings = []setting{
+	{Path: "app.app_port", Env: []string{"APP_PORT"}, Default: "8080"},
+	{Path: "app.app_env", Env: []string{"APP_ENV"}, Default: EnvDevelopment},
+	{Path: "app.jwt_secret_key", Env: []string{"JWT_SECRET_KEY"}, Secret: true},
+	{Path: "app.jwt_issuer", Env: []string{"JWT_ISSUER"}, Default: "sayur-api"},
+	{Path: "app.default_language", Env: []string{"DEFAULT_LANGUAGE"}, Default: "en"},
+
+	{Path: "db.host", Env: []string{"DATABASE_HOST"}, Default: "localhost"},
+	{Path: "db.port", Env: []string{"DATABASE_PORT"}, Default: 5432},
+	{Path: "db.user", Env: []string{"DATABASE_USER"}, Default: "postgres"},
+	{Path: "db.password", Env: []string{"DATABASE_PASSWORD"}, Secret: true},
+	{Path: "db.db_name", Env: []string{"DATABASE_NAME"}, Default: "sayur-user-service"},
+	{Path: "db.db_max_open", Env: []string{"DATABASE_MAX_OPEN_CONNECTION", "DATABASE_MAX_OPEN"}, Default: 10},
+	{Path: "db.db_max_idle", Env: []string{"DATABASE_MAX_IDLE_CONNECTION", "DATABASE_MAX_IDLE"}, Default: 5},
+	{Path: "db.auto_migrate", Env: []string{"DATABASE_AUTO_MIGRATE"}, Default: false},
+	{Path: "db.query_timeout", Env: []string{"DATABASE_QUERY_TIMEOUT"}, Default: 5 * time.Second},
+	{Path: "db.slow_query_threshold", Env: []string{"DATABASE_SLOW_QUERY_THRESHOLD"}, Default: 200 * time.Millisecond},
+
+	{Path: "rabbitmq.host", Env: []string{"RABBITMQ_HOST"}, Default: "localhost"},
+	{Path: "rabbitmq.port", Env: []string{"RABBITMQ_PORT"}, Default: 5672},
+	{Path: "rabbitmq.user", Env: []string{"RABBITMQ_USER"}, Default: "guest"},
+	{Path: "rabbitmq.password", Env: []string{"RABBITMQ_PASSWORD"}, Secret: true},
+
+	{Path: "redis.mode", Env: []string{"REDIS_MODE"}, Default: RedisModeStandalone},
+	{Path: "redis.addrs", Env: []string{"REDIS_ADDRESS"}, Default: []string{"localhost:6379"}},
+	{Path: "redis.master_name", Env: []string{"REDIS_MASTER_NAME"}},
+	{Path: "redis.password", Env: []string{"REDIS_PASSWORD"}, Secret: true},
+	{Path: "redis.db", Env: []string{"REDIS_DB"}, Default: 0},
+	{Path: "redis.tls", Env: []string{"REDIS_TLS"}, Default: false},
+	{Path: "redis.pool_size", Env: []string{"REDIS_POOL_SIZE"}, Default: 10},
+	{Path: "redis.dial_timeout", Env: []string{"REDIS_DIAL_TIMEOUT"}, Default: 5 * time.Second},
+	{Path: "redis.read_timeout", Env: []string{"REDIS_READ_TIMEOUT"}, Default: 3 * time.Second},
+	{Path: "redis.write_timeout", Env: []string{"REDIS_WRITE_TIMEOUT"}, Default: 3 * time.Second},
+
+	{Path: "session.driver", Env: []string{"SESSION_DRIVER"}, Default: "redis"},
+	{Path: "session.ttl", Env: []string{"SESSION_TTL"}, Default: 23 * time.Hour},
+
+	{Path: "message.broker", Env: []string{"MESSAGE_BROKER"}, Default: "rabbitmq"},
+	{Path: "kafka.brokers", Env: []string{"KAFKA_BROKERS"}, Default: []string{}},
+	{Path: "kafka.topic_prefix", Env: []string{"KAFKA_TOPIC_PREFIX"}},
+
+	{Path: "log.level", Env: []string{"LOG_LEVEL"}, Default: "info"},
+	{Path: "log.format", Env: []string{"LOG_FORMAT"}},
+
+	{Path: "health.check_timeout", Env: []string{"HEALTH_CHECK_TIMEOUT"}, Default: 2 * time.Second},
+	{Path: "health.cache_ttl", Env: []string{"HEALTH_CACHE_TTL"}, Default: 5 * time.Second},
+	{Path: "health.drain_delay", Env: []string{"HEALTH_DRAIN_DELAY"}, Default: 5 * time.Second},
+
+	{Path: "tracing.exporter", Env: []string{"TRACING_EXPORTER"}, Default: "none"},
+	{Path: "tracing.endpoint", Env: []string{"OTEL_EXPORTER_OTLP_ENDPOINT"}, Default: "localhost:4317"},
+	{Path: "tracing.protocol", Env: []string{"OTEL_EXPORTER_OTLP_PROTOCOL"}, Default: "grpc"},
+	{Path: "tracing.insecure", Env: []string{"OTEL_EXPORTER_OTLP_INSECURE"}, Default: true},
+	{Path: "tracing.sample_ratio", Env: []string{"TRACING_SAMPLE_RATIO"}, Default: 1.0},
+	{Path: "tracing.service_name", Env: []string{"OTEL_SERVICE_NAME"}, Default: "user-service"},
+
+	{Path: "secrets.provider", Env: []string{"SECRET_PROVIDER"}},
+	{Path: "secrets.refresh_interval", Env: []string{"SECRET_REFRESH_INTERVAL"}, Default: 5 * time.Minute},
+	{Path: "vault.address", Env: []string{"VAULT_ADDR"}, Default: "http://localhost:8200"},
+	{Path: "vault.token", Env: []string{"VAULT_TOKEN"}, Secret: true},
+	{Path: "vault.mount", Env: []string{"VAULT_KV_MOUNT"}, Default: "secret"},
+	{Path: "vault.path", Env: []string{"VAULT_SECRET_PATH"}, Default: "sayur/user-service"},
+
+	{Path: "grpc.enabled", Env: []string{"GRPC_ENABLED"}, Default: false},
+	{Path: "grpc.port", Env: []string{"GRPC_PORT"}, Default: 9090},
+	{Path: "grpc.cert_file", Env: []string{"GRPC_TLS_CERT_FILE"}},
+	{Path: "grpc.key_file", Env: []string{"GRPC_TLS_KEY_FILE"}},
+	{Path: "grpc.client_ca_file", Env: []string{"GRPC_TLS_CLIENT_CA_FILE"}},
+	{Path: "grpc.auth_token", Env: []string{"GRPC_AUTH_TOKEN"}, Secret: true},
+	{Path: "grpc.shutdown_timeout", Env: []string{"GRPC_SHUTDOWN_TIMEOUT"}, Default: 5 * time.Second},
+
+	{Path: "seed.admin_name", Env: []string{"SEED_ADMIN_NAME"}, Default: "super admin"},
+	{Path: "seed.admin_email", Env: []string{"SEED_ADMIN_EMAIL"}, Default: "superadmin@gmail.com"},
+	{Path: "seed.admin_password", Env: []string{"SEED_ADMIN_PASSWORD"}, Secret: true},
+	{Path: "seed.demo_customers", Env: []string{"SEED_DEMO_CUSTOMERS"}, Default: 25},
+
+	{Path: "token.email_verification_ttl", Env: []string{"TOKEN_EMAIL_VERIFICATION_TTL"}, Default: 24 * time.Hour},
+	{Path: "token.reset_password_ttl", Env: []string{"TOKEN_RESET_PASSWORD_TTL"}, Default: time.Hour},
+	{Path: "token.resend_cooldown", Env: []string{"TOKEN_RESEND_COOLDOWN"}, Default: time.Minute},
+
+	{Path: "links.verify_email.web", Env: []string{"LINK_VERIFY_EMAIL_WEB"}, Default: "http://localhost:8080/api/v1/verify-account?token={token}"},
+	{Path: "links.verify_email.android", Env: []string{"LINK_VERIFY_EMAIL_ANDROID"}},
+	{Path: "links.verify_email.ios", Env: []string{"LINK_VERIFY_EMAIL_IOS"}},
+	{Path: "links.reset_password.web", Env: []string{"LINK_RESET_PASSWORD_WEB"}, Default: "http://localhost:8080/forgot-password?token={token}"},
+	{Path: "links.reset_password.android", Env: []string{"LINK_RESET_PASSWORD_ANDROID"}},
+	{Path: "links.reset_password.ios", Env: []string{"LINK_RESET_PASSWORD_IOS"}},
+
+	{Path: "scheduler.enabled", Env: []string{"SCHEDULER_ENABLED"}, Default: true},
+	{Path: "scheduler.token_cleanup_schedule", Env: []string{"SCHEDULER_TOKEN_CLEANUP_SCHEDULE"}, Default: "@hourly"},
+	{Path: "scheduler.session_cleanup_schedule", Env: []string{"SCHEDULER_SESSION_CLEANUP_SCHEDULE"}, Default: "@every 15m"},
+	{Path: "scheduler.unverified_users_schedule", Env: []string{"SCHEDULER_UNVERIFIED_USERS_SCHEDULE"}, Default: "@daily"},
+	{Path: "scheduler.token_retention", Env: []string{"SCHEDULER_TOKEN_RETENTION"}, Default: 7 * 24 * time.Hour},
+	{Path: "scheduler.unverified_remind_days", Env: []string{"SCHEDULER_UNVERIFIED_REMIND_DAYS"}, Default: 3},
+	{Path: "scheduler.unverified_remind_retry", Env: []string{"SCHEDULER_UNVERIFIED_REMIND_RETRY"}, Default: 6 * time.Hour},
+	{Path: "scheduler.unverified_delete_days", Env: []string{"SCHEDULER_UNVERIFIED_DELETE_DAYS"}, Default: 30},
+
+	{Path: "password.min_length", Env: []string{"PASSWORD_MIN_LENGTH"}, Default: 8},
+	{Path: "password.max_length", Env: []string{"PASSWORD_MAX_LENGTH"}, Default: 128},
+	{Path: "password.require_upper", Env: []string{"PASSWORD_REQUIRE_UPPER"}, Default: false},
+	{Path: "password.require_lower", Env: []string{"PASSWORD_REQUIRE_LOWER"}, Default: false},
+	{Path: "password.require_digit", Env: []string{"PASSWORD_REQUIRE_DIGIT"}, Default: false},
+	{Path: "password.require_symbol", Env: []string{"PASSWORD_REQUIRE_SYMBOL"}, Default: false},
+	{Path: "password.reject_personal", Env: []string{"PASSWORD_REJECT_PERSONAL"}, Default: true},
+	{Path: "password.blocklist_file", Env: []string{"PASSWORD_BLOCKLIST_FILE"}},
+	{Path: "password.breach_provider", Env: []string{"PASSWORD_BREACH_PROVIDER"}, Default: "none"},
+	{Path: "password.breach_list_file", Env: []string{"PASSWORD_BREACH_LIST_FILE"}},
+	{Path: "password.breach_api_url", Env: []string{"PASSWORD_BREACH_API_URL"}, Default: "https://api.pwnedpasswords.com"},
+	{Path: "password.breach_timeout", Env: []string{"PASSWORD_BREACH_TIMEOUT"}, Default: 2 * time.Second},
+	{Path: "password.argon2_memory", Env: []string{"PASSWORD_ARGON2_MEMORY"}, Default: 19456},
+	{Path: "password.argon2_time", Env: []string{"PASSWORD_ARGON2_TIME"}, Default: 2},
+	{Path: "password.argon2_parallelism", Env: []string{"PASSWORD_ARGON2_PARALLELISM"}, Default: 1},
+}
+
+// ResolveFile returns the config file to load: path when given, otherwise
+// .env when it exists in the working directory.
+func ResolveFile(path string) string {
+	if path != "" {
+		return path
+	}
+	if _, err := os.Stat(DefaultConfigFile); err == nil {
+		return DefaultConfigFile
+	}
+	return ""
+}
+
+// Load builds the Config from defaults, the optional file and the
+// environment, in increasing order of precedence. The file may be a .env,
+// YAML or JSON document. Any variable can also be read from a file named by
+// NAME_FILE. When SECRET_PROVIDER is set, values fetched from the provider
+// override everything else. Load does not validate, call Validate for that.
+func Load(path string) (*Config, error) {
+	v := viper.New()
+	for _, s := range settings {
+		if s.Default != nil {
+			v.SetDefault(s.Path, s.Default)
+		}
+		if err := v.BindEnv(append([]string{s.Path}, s.Env...)...); err != nil {
+			return nil, err
+		}
+	}
+
+	if path != "" {
+		if err := readConfigFile(v, path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		value, ok, err := fileValue(s.Env)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			v.Set(s.Path, value)
+		}
+	}
+
+	cfg, err := decode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := cfg.NewSecretProvider()
+	if err != nil || provider == nil {
+		return cfg, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	secrets, err := provider.Secrets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secrets from %s: %w", cfg.Secrets.Provider, err)
+	}
+	applySecrets(v, secrets)
+
+	if cfg, err = decode(v); err != nil {
+		return nil, err
+	}
+	cfg.SecretValues = secrets
+
+	return cfg, nil
+}
+
+func decode(v *viper.Viper) (*Config, error) {
+	cfg := &Config{}
+	err := v.Unmarshal(cfg, func(dc *mapstructure.DecoderConfig) {
+		dc.TagName = "json"
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	return cfg, nil
+}
+
+// applySecrets overrides every setting whose environment variable name is a
+// key of secrets.
+func applySecrets(v *viper.Viper, secrets map[string]string) {
+	for _, s := range settings {
+		for _, env := range s.Env {
+			if value, ok := secrets[env]; ok {
+				v.Set(s.Path, value)
+				break
+			}
+		}
+	}
+}
+
+// IsSecretKey reports whether the environment variable name belongs to a
+// setting that holds a credential.
+func IsSecretKey(env string) bool {
+	for _, s := range settings {
+		for _, e := range s.Env {
+			if e == env {
+				return s.Secret
+			}
+		}
+	}
+	return false
+}
+
+func readConfigFile(v *viper.Viper, path string) error {
+	switch ext := strings.ToLower(filepath.Ext(path)); {
+	case ext == ".yaml", ext == ".yml", ext == ".json":
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		return nil
+	case ext == ".env", strings.HasPrefix(filepath.Base(path), ".env"):
+		values, err := gotenv.Read(path)
+		if err != nil {
+			return fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		return v.MergeConfigMap(envToConfigMap(values))
+	default:
+		return fmt.Errorf("unsupported config file %s: use .env, .yaml, .yml or .json", path)
+	}
+}
+
+// envToConfigMap maps KEY=value pairs from a .env file onto the nested
+// structure used by YAML/JSON files.
+func envToConfigMap(values map[string]string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, s := range settings {
+		for _, env := range s.Env {
+			value, ok := values[env]
+			if !ok {
+				continue
+			}
+
+			parts := strings.Split(s.Path, ".")
+			node := out
+			for _, part := range parts[:len(parts)-1] {
+				child, ok := node[part].(map[string]interface{})
+				if !ok {
+					child = map[string]interface{}{}
+					node[part] = child
+				}
+				node = child
+			}
+			node[parts[len(parts)-1]] = value
+			break
+		}
+	}
+	return out
+}
+
+// Setting is one effective configuration value as reported by Settings.
+type Setting struct {
+	Path  string
+	Env   string
+	Value string
+}
+
+// Settings lists every known key with its effective value. Secrets are
+// masked when set so the output is safe to print.
+func (c Config) Settings() []Setting {
+	out := make([]Setting, 0, len(settings))
+	for _, s := range settings {
+		value := formatValue(lookupPath(reflect.ValueOf(c), s.Path))
+		if s.Secret && value != "" {
+			value = "******"
+		}
+		out = append(out, Setting{Path: s.Path, Env: s.Env[0], Value: value})
+	}
+	return out
+}
+
+func envName(path string) string {
+	for _, s := range settings {
+		if s.Path == path {
+			return s.Env[0]
+		}
+	}
+	return ""
+}
+
+func lookupPath(value reflect.Value, path string) reflect.Value {
+	for _, part := range strings.Split(path, ".") {
+		field, ok := fieldByTag(value, part)
+		if !ok {
+			return reflect.Value{}
+		}
+		value = field
+	}
+	return value
+}
+
+func fieldByTag(value reflect.Value, tag string) (reflect.Value, bool) {
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		if name == tag {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func formatValue(value reflect.Value) string {
+	if !value.IsValid() {
+		return ""
+	}
+	if value.Kind() == reflect.Slice {
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(value.Index(i).Interface())
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value.Interface())
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"log/slog"
+	"user-service/utils/logger"
+)
+
+// NewLogger builds the structured logger shared by every component. Unless
+// LOG_FORMAT says otherwise, logs are JSON everywhere but in development.
+func (cfg Config) NewLogger() *slog.Logger {
+	format := cfg.Log.Format
+	if format == "" {
+		format = logger.FormatJSON
+		if cfg.App.AppEnv == EnvDevelopment {
+			format = logger.FormatText
+		}
+	}
+
+	return logger.New(logger.Options{
+		Level:  cfg.Log.Level,
+		Format: format,
+	})
+}
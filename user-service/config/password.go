@@ -0,0 +1,16 @@
+package config
+
+import "user-service/utils/password"
+
+// legacyBcryptCost is the cost passwords were hashed with before Argon2id.
+const legacyBcryptCost = 14
+
+// NewPasswordHasher returns the hasher of passwords: Argon2id with the
+// configured parameters, still verifying the bcrypt hashes made before it.
+func (cfg Config) NewPasswordHasher() *password.Hasher {
+	return password.NewHasher(password.Argon2id{
+		Memory:      uint32(cfg.Password.Argon2Memory),
+		Time:        uint32(cfg.Password.Argon2Time),
+		Parallelism: uint8(cfg.Password.Argon2Parallelism),
+	}, password.Bcrypt{Cost: legacyBcryptCost})
+}
@@ -1,18 +1,44 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"strconv"
+	"time"
 
 	"github.com/streadway/amqp"
 )
 
-func (cfg Config) NewRabbitMQ() (*amqp.Connection, error) {
+// rabbitMQDialTimeout bounds the dial and handshake when ctx has no deadline.
+const rabbitMQDialTimeout = 30 * time.Second
+
+// NewRabbitMQ connects to RabbitMQ, giving up when ctx is done.
+func (cfg Config) NewRabbitMQ(ctx context.Context) (*amqp.Connection, error) {
 	url := fmt.Sprintf("amqp://%s:%s@%s:%s/", cfg.RabbitMQ.User, cfg.RabbitMQ.Password, cfg.RabbitMQ.Host, strconv.Itoa(cfg.RabbitMQ.Port))
-	conn, err := amqp.Dial(url)
+	conn, err := amqp.DialConfig(url, amqp.Config{
+		Heartbeat: 10 * time.Second,
+		Locale:    "en_US",
+		Dial: func(network, addr string) (net.Conn, error) {
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			// amqp clears the deadline once the handshake is done
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				deadline = time.Now().Add(rabbitMQDialTimeout)
+			}
+			if err := conn.SetDeadline(deadline); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return conn, nil
+		},
+	})
 	if err != nil {
-		fmt.Printf("[NewRabbitMQ-1] Failed to connect to RabbitMQ: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
 
 	return conn, nil
@@ -2,23 +2,125 @@ package config
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync/atomic"
 
 	"github.com/go-redis/redis/v8"
 )
 
-var Ctx = context.Background()
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
+// RedisClient is the shared Redis client. Connections authenticate with the
+// current password when they are opened, so RotatePassword applies to new
+// connections without rebuilding the client.
+type RedisClient struct {
+	redis.UniversalClient
+
+	cfg      Redis
+	password atomic.Pointer[string]
+}
+
+// NewRedisClient builds the Redis client described by cfg.Redis and checks
+// that it is reachable. The client holds its own pool and is meant to be
+// created once at startup and shared.
+func (cfg Config) NewRedisClient(ctx context.Context) (*RedisClient, error) {
+	r := &RedisClient{cfg: cfg.Redis}
+	r.password.Store(&cfg.Redis.Password)
 
-func NewRedisClient() *redis.Client {
-	client := redis.NewClient(&redis.Options{
-		Addr:     "localhost:6379",
-		Password: "", // no password set
-		DB:       0,  // use default DB
-	})
+	client, err := newRedisClient(cfg.Redis, func() string { return *r.password.Load() })
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	r.UniversalClient = client
+	return r, nil
+}
 
-	_, err := client.Ping(Ctx).Result()
+// RotatePassword checks password with a separate client and, when it is
+// accepted, has every new connection use it. The old password is kept when
+// the new one is refused.
+func (r *RedisClient) RotatePassword(ctx context.Context, password string) error {
+	client, err := newRedisClient(r.cfg, func() string { return password })
 	if err != nil {
-		panic(err)
+		return err
 	}
+	defer client.Close()
 
-	return client
+	if err := client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("connect with the rotated redis password: %w", err)
+	}
+	r.password.Store(&password)
+	return nil
+}
+
+// newRedisClient builds a client authenticating every new connection with
+// password(). AUTH and SELECT run in OnConnect rather than from the options,
+// so a replaced password is read when the connection opens.
+func newRedisClient(cfg Redis, password func() string) (redis.UniversalClient, error) {
+	if len(cfg.Addrs) == 0 {
+		return nil, errors.New("redis address is not configured")
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.TLS {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	onConnect := func(ctx context.Context, cn *redis.Conn) error {
+		if pw := password(); pw != "" {
+			if err := cn.Auth(ctx, pw).Err(); err != nil {
+				return err
+			}
+		}
+		if cfg.DB > 0 && cfg.Mode != RedisModeCluster {
+			return cn.Select(ctx, cfg.DB).Err()
+		}
+		return nil
+	}
+
+	switch cfg.Mode {
+	case "", RedisModeStandalone:
+		return redis.NewClient(&redis.Options{
+			Addr:         cfg.Addrs[0],
+			OnConnect:    onConnect,
+			PoolSize:     cfg.PoolSize,
+			DialTimeout:  cfg.DialTimeout,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			TLSConfig:    tlsConfig,
+		}), nil
+	case RedisModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    cfg.MasterName,
+			SentinelAddrs: cfg.Addrs,
+			OnConnect:     onConnect,
+			PoolSize:      cfg.PoolSize,
+			DialTimeout:   cfg.DialTimeout,
+			ReadTimeout:   cfg.ReadTimeout,
+			WriteTimeout:  cfg.WriteTimeout,
+			TLSConfig:     tlsConfig,
+		}), nil
+	case RedisModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        cfg.Addrs,
+			OnConnect:    onConnect,
+			PoolSize:     cfg.PoolSize,
+			DialTimeout:  cfg.DialTimeout,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			TLSConfig:    tlsConfig,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", cfg.Mode)
+	}
 }
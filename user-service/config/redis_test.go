@@ -0,0 +1,42 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisClientRotatePassword(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("old")
+
+	cfg := Config{Redis: Redis{Addrs: []string{server.Addr()}, Password: "old"}}
+	client, err := cfg.NewRedisClient(context.Background())
+	if err != nil {
+		t.Fatalf("NewRedisClient: %v", err)
+	}
+	defer client.Close()
+
+	server.RequireAuth("new")
+
+	if err := client.RotatePassword(context.Background(), "wrong"); err == nil {
+		t.Fatal("RotatePassword accepted a password the server refuses")
+	}
+	if got := *client.password.Load(); got != "old" {
+		t.Fatalf("password = %q after a refused rotation, want the old one kept", got)
+	}
+
+	if err := client.RotatePassword(context.Background(), "new"); err != nil {
+		t.Fatalf("RotatePassword: %v", err)
+	}
+
+	// drop the pooled connections so the next command dials again
+	server.Close()
+	if err := server.Restart(); err != nil {
+		t.Fatalf("restart miniredis: %v", err)
+	}
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Errorf("Ping on a new connection = %v, want it to authenticate with the rotated password", err)
+	}
+}
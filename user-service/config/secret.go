@@ -0,0 +1,167 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const SecretProviderVault = "vault"
+
+// SecretProviderInterface fetches secret values keyed by the environment
+// variable name of the setting they replace, e.g. "JWT_SECRET_KEY" or
+// "DATABASE_PASSWORD".
+type SecretProviderInterface interface {
+	Secrets(ctx context.Context) (map[string]string, error)
+}
+
+// NewSecretProvider returns the provider selected by SECRET_PROVIDER, or nil
+// when secrets only come from the environment and files.
+func (cfg Config) NewSecretProvider() (SecretProviderInterface, error) {
+	switch cfg.Secrets.Provider {
+	case "":
+		return nil, nil
+	case SecretProviderVault:
+		return NewVaultSecretProvider(cfg.Vault)
+	default:
+		return nil, fmt.Errorf("unknown secret provider %q", cfg.Secrets.Provider)
+	}
+}
+
+// fileValue resolves the NAME_FILE convention used by Docker and Kubernetes
+// secrets: when NAME is unset and NAME_FILE points to a file, the trimmed
+// file content is the value.
+func fileValue(envs []string) (string, bool, error) {
+	for _, env := range envs {
+		if _, ok := os.LookupEnv(env); ok {
+			return "", false, nil
+		}
+	}
+
+	for _, env := range envs {
+		path, ok := os.LookupEnv(env + "_FILE")
+		if !ok || path == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read %s_FILE: %w", env, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), true, nil
+	}
+	return "", false, nil
+}
+
+// PasswordRotator is a client that can switch to a rotated password without
+// a restart. RotatePassword connects with the new password first and keeps
+// the old one when that fails.
+type PasswordRotator interface {
+	RotatePassword(ctx context.Context, password string) error
+}
+
+// SecretWatcher polls a secret provider and applies values that changed
+// since the previous poll, so rotated credentials can be picked up without a
+// restart. A value that could not be applied is retried on the next poll.
+type SecretWatcher struct {
+	log      *slog.Logger
+	provider SecretProviderInterface
+	interval time.Duration
+
+	mu      sync.Mutex
+	current map[string]string
+	failed  map[string]error
+}
+
+func NewSecretWatcher(log *slog.Logger, provider SecretProviderInterface, interval time.Duration, current map[string]string) *SecretWatcher {
+	applied := make(map[string]string, len(current))
+	for key, value := range current {
+		applied[key] = value
+	}
+
+	return &SecretWatcher{
+		log:      log,
+		provider: provider,
+		interval: interval,
+		current:  applied,
+		failed:   map[string]error{},
+	}
+}
+
+// Watch blocks until ctx is done, calling apply for every key whose value
+// changed since it was last applied.
+func (w *SecretWatcher) Watch(ctx context.Context, apply func(ctx context.Context, key, value string) error) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.refresh(ctx, apply)
+		}
+	}
+}
+
+func (w *SecretWatcher) refresh(ctx context.Context, apply func(ctx context.Context, key, value string) error) {
+	secrets, err := w.provider.Secrets(ctx)
+	if err != nil {
+		w.log.ErrorContext(ctx, "failed to refresh secrets", "error", err)
+		return
+	}
+
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := secrets[key]
+		w.mu.Lock()
+		unchanged := w.current[key] == value
+		w.mu.Unlock()
+		if unchanged {
+			continue
+		}
+
+		err := apply(ctx, key, value)
+
+		w.mu.Lock()
+		if err != nil {
+			w.failed[key] = err
+		} else {
+			w.current[key] = value
+			delete(w.failed, key)
+		}
+		w.mu.Unlock()
+
+		if err != nil {
+			w.log.ErrorContext(ctx, "failed to apply rotated secret, retrying on the next refresh", "key", key, "error", err)
+		}
+	}
+}
+
+// Check reports the rotated secrets that could not be applied, for use as a
+// readiness check.
+func (w *SecretWatcher) Check(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.failed) == 0 {
+		return nil
+	}
+
+	problems := make([]string, 0, len(w.failed))
+	for key, err := range w.failed {
+		problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("rotated secrets not applied: %s", strings.Join(problems, "; "))
+}
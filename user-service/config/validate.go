@@ -0,0 +1,284 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"user-service/utils/i18n"
+	"user-service/utils/link"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// ValidationError lists every problem found in a Config.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+type validation struct {
+	problems []string
+}
+
+func (v *validation) addf(path, format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf("%s (%s) ", path, envName(path))+fmt.Sprintf(format, args...))
+}
+
+func (v *validation) required(path, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf(path, "is required")
+	}
+}
+
+func (v *validation) oneOf(path, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	quoted := make([]string, len(allowed))
+	for i, a := range allowed {
+		quoted[i] = fmt.Sprintf("%q", a)
+	}
+	v.addf(path, "must be one of %s, got %q", strings.Join(quoted, ", "), value)
+}
+
+func (v *validation) port(path string, value int) {
+	if value < 1 || value > 65535 {
+		v.addf(path, "must be between 1 and 65535, got %d", value)
+	}
+}
+
+func (v *validation) schedule(path, value string) {
+	if _, err := cron.ParseStandard(value); err != nil {
+		v.addf(path, "must be a cron schedule, got %q: %v", value, err)
+	}
+}
+
+// link checks an optional link template.
+func (v *validation) link(path, template string) {
+	if template == "" {
+		return
+	}
+	if err := link.Check(template); err != nil {
+		v.addf(path, "%v, got %q", err, template)
+	}
+}
+
+// Validate checks required fields and value ranges and reports all problems
+// at once.
+func (c *Config) Validate() error {
+	v := &validation{}
+
+	v.required("app.app_port", c.App.AppPort)
+	v.oneOf("app.app_env", c.App.AppEnv, EnvDevelopment, EnvStaging, EnvProduction)
+	v.required("app.jwt_secret_key", c.App.JwtSecretKey)
+	if c.App.AppEnv == EnvProduction && len(c.App.JwtSecretKey) < 32 {
+		v.addf("app.jwt_secret_key", "must be at least 32 characters in production")
+	}
+	v.required("app.jwt_issuer", c.App.JwtIssuer)
+	v.oneOf("app.default_language", c.App.DefaultLanguage, i18n.Supported...)
+
+	v.required("db.host", c.Psql.Host)
+	v.port("db.port", c.Psql.Port)
+	v.required("db.user", c.Psql.User)
+	v.required("db.db_name", c.Psql.DBName)
+	if c.Psql.DBMaxOpen < 1 {
+		v.addf("db.db_max_open", "must be at least 1, got %d", c.Psql.DBMaxOpen)
+	}
+	if c.Psql.DBMaxIdle < 0 || c.Psql.DBMaxIdle > c.Psql.DBMaxOpen {
+		v.addf("db.db_max_idle", "must be between 0 and db.db_max_open (%d), got %d", c.Psql.DBMaxOpen, c.Psql.DBMaxIdle)
+	}
+	if c.Psql.QueryTimeout < 0 {
+		v.addf("db.query_timeout", "must not be negative, got %s", c.Psql.QueryTimeout)
+	}
+	if c.Psql.SlowQueryThreshold <= 0 {
+		v.addf("db.slow_query_threshold", "must be positive, got %s", c.Psql.SlowQueryThreshold)
+	}
+
+	v.oneOf("redis.mode", c.Redis.Mode, RedisModeStandalone, RedisModeSentinel, RedisModeCluster)
+	if len(c.Redis.Addrs) == 0 {
+		v.addf("redis.addrs", "is required")
+	}
+	if c.Redis.Mode == RedisModeSentinel {
+		v.required("redis.master_name", c.Redis.MasterName)
+	}
+	if c.Redis.Mode == RedisModeCluster && c.Redis.DB != 0 {
+		v.addf("redis.db", "must be 0 in cluster mode, got %d", c.Redis.DB)
+	}
+	if c.Redis.DB < 0 {
+		v.addf("redis.db", "must not be negative, got %d", c.Redis.DB)
+	}
+	if c.Redis.PoolSize < 1 {
+		v.addf("redis.pool_size", "must be at least 1, got %d", c.Redis.PoolSize)
+	}
+	if c.Redis.DialTimeout <= 0 || c.Redis.ReadTimeout <= 0 || c.Redis.WriteTimeout <= 0 {
+		v.addf("redis.dial_timeout", "dial, read and write timeouts must be positive")
+	}
+
+	v.oneOf("session.driver", c.Session.Driver, "redis", "postgres", "memory")
+	if c.Session.TTL <= 0 {
+		v.addf("session.ttl", "must be positive, got %s", c.Session.TTL)
+	}
+
+	v.oneOf("message.broker", c.Message.Broker, "rabbitmq", "kafka", "memory")
+	switch c.Message.Broker {
+	case "rabbitmq":
+		v.required("rabbitmq.host", c.RabbitMQ.Host)
+		v.port("rabbitmq.port", c.RabbitMQ.Port)
+		v.required("rabbitmq.user", c.RabbitMQ.User)
+	case "kafka":
+		if len(c.Kafka.Brokers) == 0 {
+			v.addf("kafka.brokers", "is required when message.broker is kafka")
+		}
+	}
+	if c.App.AppEnv == EnvProduction && (c.Message.Broker == "memory" || c.Session.Driver == "memory") {
+		v.addf("message.broker", "in-memory broker and session store are not allowed in production")
+	}
+
+	v.oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+	v.oneOf("log.format", c.Log.Format, "", "json", "text")
+
+	if c.Health.CheckTimeout <= 0 {
+		v.addf("health.check_timeout", "must be positive, got %s", c.Health.CheckTimeout)
+	}
+	if c.Health.CacheTTL < 0 || c.Health.DrainDelay < 0 {
+		v.addf("health.cache_ttl", "cache ttl and drain delay must not be negative")
+	}
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "none", "otlp", "stdout")
+	if c.Tracing.Exporter == "otlp" {
+		v.required("tracing.endpoint", c.Tracing.Endpoint)
+		v.oneOf("tracing.protocol", c.Tracing.Protocol, "grpc", "http")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		v.addf("tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+
+	v.oneOf("secrets.provider", c.Secrets.Provider, "", SecretProviderVault)
+	if c.Secrets.Provider == SecretProviderVault {
+		v.required("vault.address", c.Vault.Address)
+		v.required("vault.token", c.Vault.Token)
+		v.required("vault.path", c.Vault.Path)
+	}
+	if c.Secrets.RefreshInterval < 0 {
+		v.addf("secrets.refresh_interval", "must not be negative, got %s", c.Secrets.RefreshInterval)
+	}
+
+	if c.GRPC.Enabled {
+		v.port("grpc.port", c.GRPC.Port)
+		if c.GRPC.Port == c.appPort() {
+			v.addf("grpc.port", "must differ from app.app_port")
+		}
+		if (c.GRPC.CertFile == "") != (c.GRPC.KeyFile == "") {
+			v.addf("grpc.cert_file", "grpc.cert_file and grpc.key_file must be set together")
+		}
+		if c.GRPC.ClientCAFile != "" && c.GRPC.CertFile == "" {
+			v.addf("grpc.client_ca_file", "mutual TLS needs grpc.cert_file and grpc.key_file")
+		}
+		// only development may expose the server without authentication
+		if c.App.AppEnv != EnvDevelopment && c.GRPC.ClientCAFile == "" && c.GRPC.AuthToken == "" {
+			v.addf("grpc.auth_token", "%s needs mutual TLS or an auth token for the gRPC server, or grpc.enabled=false", c.App.AppEnv)
+		}
+		if c.GRPC.ShutdownTimeout <= 0 {
+			v.addf("grpc.shutdown_timeout", "must be positive, got %s", c.GRPC.ShutdownTimeout)
+		}
+	}
+
+	v.required("seed.admin_email", c.Seed.AdminEmail)
+	if c.Seed.AdminPassword != "" && len(c.Seed.AdminPassword) < 8 {
+		v.addf("seed.admin_password", "must be at least 8 characters")
+	}
+	if c.Seed.DemoCustomers < 0 {
+		v.addf("seed.demo_customers", "must not be negative, got %d", c.Seed.DemoCustomers)
+	}
+
+	if c.Token.EmailVerificationTTL <= 0 {
+		v.addf("token.email_verification_ttl", "must be positive, got %s", c.Token.EmailVerificationTTL)
+	}
+	if c.Token.ResetPasswordTTL <= 0 {
+		v.addf("token.reset_password_ttl", "must be positive, got %s", c.Token.ResetPasswordTTL)
+	}
+	if c.Token.ResendCooldown < 0 {
+		v.addf("token.resend_cooldown", "must not be negative, got %s", c.Token.ResendCooldown)
+	}
+
+	v.required("links.verify_email.web", c.Links.VerifyEmail.Web)
+	v.required("links.reset_password.web", c.Links.ResetPassword.Web)
+	v.link("links.verify_email.web", c.Links.VerifyEmail.Web)
+	v.link("links.verify_email.android", c.Links.VerifyEmail.Android)
+	v.link("links.verify_email.ios", c.Links.VerifyEmail.IOS)
+	v.link("links.reset_password.web", c.Links.ResetPassword.Web)
+	v.link("links.reset_password.android", c.Links.ResetPassword.Android)
+	v.link("links.reset_password.ios", c.Links.ResetPassword.IOS)
+
+	if c.Scheduler.Enabled {
+		v.schedule("scheduler.token_cleanup_schedule", c.Scheduler.TokenCleanupSchedule)
+		v.schedule("scheduler.session_cleanup_schedule", c.Scheduler.SessionCleanupSchedule)
+		v.schedule("scheduler.unverified_users_schedule", c.Scheduler.UnverifiedUsersSchedule)
+		if c.Scheduler.TokenRetention < 0 {
+			v.addf("scheduler.token_retention", "must not be negative, got %s", c.Scheduler.TokenRetention)
+		}
+		if c.Scheduler.UnverifiedRemindDays < 0 {
+			v.addf("scheduler.unverified_remind_days", "must not be negative, got %d", c.Scheduler.UnverifiedRemindDays)
+		}
+		if c.Scheduler.UnverifiedRemindRetry < 0 {
+			v.addf("scheduler.unverified_remind_retry", "must not be negative, got %s", c.Scheduler.UnverifiedRemindRetry)
+		}
+		if c.Scheduler.UnverifiedDeleteDays < 0 {
+			v.addf("scheduler.unverified_delete_days", "must not be negative, got %d", c.Scheduler.UnverifiedDeleteDays)
+		}
+		if c.Scheduler.UnverifiedRemindDays > 0 && c.Scheduler.UnverifiedDeleteDays > 0 &&
+			c.Scheduler.UnverifiedRemindDays >= c.Scheduler.UnverifiedDeleteDays {
+			v.addf("scheduler.unverified_remind_days", "must be less than scheduler.unverified_delete_days (%d), got %d", c.Scheduler.UnverifiedDeleteDays, c.Scheduler.UnverifiedRemindDays)
+		}
+	}
+
+	// sign in still rejects passwords shorter than 8 characters
+	if c.Password.MinLength < 8 {
+		v.addf("password.min_length", "must be at least 8, got %d", c.Password.MinLength)
+	}
+	if c.Password.MaxLength < c.Password.MinLength {
+		v.addf("password.max_length", "must be at least password.min_length (%d), got %d", c.Password.MinLength, c.Password.MaxLength)
+	}
+	v.oneOf("password.breach_provider", c.Password.BreachProvider, "none", "local", "hibp")
+	switch c.Password.BreachProvider {
+	case "local":
+		v.required("password.breach_list_file", c.Password.BreachListFile)
+	case "hibp":
+		v.required("password.breach_api_url", c.Password.BreachAPIURL)
+		if c.Password.BreachTimeout <= 0 {
+			v.addf("password.breach_timeout", "must be positive, got %s", c.Password.BreachTimeout)
+		}
+	}
+
+	if c.Password.Argon2Time < 1 {
+		v.addf("password.argon2_time", "must be at least 1, got %d", c.Password.Argon2Time)
+	}
+	if c.Password.Argon2Parallelism < 1 || c.Password.Argon2Parallelism > 255 {
+		v.addf("password.argon2_parallelism", "must be between 1 and 255, got %d", c.Password.Argon2Parallelism)
+	}
+	// Argon2 needs at least 8 KiB per lane
+	if c.Password.Argon2Memory < 8*c.Password.Argon2Parallelism || c.Password.Argon2Memory > 4*1024*1024 {
+		v.addf("password.argon2_memory", "must be between %d and %d KiB, got %d", 8*c.Password.Argon2Parallelism, 4*1024*1024, c.Password.Argon2Memory)
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+func (c *Config) appPort() int {
+	port, _ := strconv.Atoi(c.App.AppPort)
+	return port
+}
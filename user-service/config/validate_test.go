@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateRequiresGRPCAuthOutsideDevelopment(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		enabled  bool
+		token    string
+		wantAuth bool
+	}{
+		{name: "development may run without auth", env: EnvDevelopment, enabled: true},
+		{name: "staging needs auth", env: EnvStaging, enabled: true, wantAuth: true},
+		{name: "production needs auth", env: EnvProduction, enabled: true, wantAuth: true},
+		{name: "staging with a token", env: EnvStaging, enabled: true, token: "t0ken"},
+		{name: "staging with grpc disabled", env: EnvStaging},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.App.AppEnv = tt.env
+			cfg.GRPC.Enabled = tt.enabled
+			cfg.GRPC.AuthToken = tt.token
+
+			var invalid *ValidationError
+			if err := cfg.Validate(); !errors.As(err, &invalid) {
+				t.Fatalf("Validate = %v, want a *ValidationError for the empty config", err)
+			}
+			var gotAuth bool
+			for _, problem := range invalid.Problems {
+				if strings.HasPrefix(problem, "grpc.auth_token ") {
+					gotAuth = true
+				}
+			}
+			if gotAuth != tt.wantAuth {
+				t.Errorf("grpc.auth_token reported = %v, want %v; problems:\n%s", gotAuth, tt.wantAuth, invalid)
+			}
+		})
+	}
+}
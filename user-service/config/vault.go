@@ -0,0 +1,71 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type vaultSecretProvider struct {
+	address string
+	token   string
+	mount   string
+	path    string
+	client  *http.Client
+}
+
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// Secrets implements SecretProviderInterface by reading the latest version of
+// a KV v2 secret. Every key of the secret is returned as a string.
+func (v *vaultSecretProvider) Secrets(ctx context.Context) (map[string]string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", v.address, v.mount, v.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body := vaultKVResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned %d for %s: %s", resp.StatusCode, v.path, strings.Join(body.Errors, "; "))
+	}
+
+	secrets := make(map[string]string, len(body.Data.Data))
+	for key, value := range body.Data.Data {
+		secrets[key] = fmt.Sprint(value)
+	}
+	return secrets, nil
+}
+
+func NewVaultSecretProvider(cfg Vault) (SecretProviderInterface, error) {
+	if cfg.Address == "" || cfg.Token == "" {
+		return nil, errors.New("vault address and token are required")
+	}
+
+	return &vaultSecretProvider{
+		address: strings.TrimRight(cfg.Address, "/"),
+		token:   cfg.Token,
+		mount:   strings.Trim(cfg.Mount, "/"),
+		path:    strings.Trim(cfg.Path, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeVault stands in for a Vault server with a KV v2 engine mounted at
+// "secret".
+type fakeVault struct {
+	token string
+
+	mu      sync.Mutex
+	secrets map[string]map[string]interface{}
+}
+
+func (f *fakeVault) set(path string, data map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.secrets[path] = data
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Header.Get("X-Vault-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{"permission denied"}})
+		return
+	}
+
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/secret/data/")
+	f.mu.Lock()
+	data, found := f.secrets[path]
+	f.mu.Unlock()
+	if r.Method != http.MethodGet || !ok || !found {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{}})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]interface{}{
+			"data":     data,
+			"metadata": map[string]interface{}{"version": 1},
+		},
+	})
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	t.Helper()
+
+	vault := &fakeVault{token: "dev-root", secrets: map[string]map[string]interface{}{}}
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+	return vault, server
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	vault, server := newFakeVault(t)
+	vault.set("user-service", map[string]interface{}{
+		"JWT_SECRET_KEY":    "s3cret",
+		"DATABASE_PASSWORD": "pg-pass",
+		"DATABASE_PORT":     5432,
+	})
+
+	tests := []struct {
+		name    string
+		cfg     Vault
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "reads the latest version",
+			cfg:  Vault{Address: server.URL + "/", Token: "dev-root", Mount: "/secret/", Path: "user-service"},
+			want: map[string]string{"JWT_SECRET_KEY": "s3cret", "DATABASE_PASSWORD": "pg-pass", "DATABASE_PORT": "5432"},
+		},
+		{
+			name:    "wrong token",
+			cfg:     Vault{Address: server.URL, Token: "nope", Mount: "secret", Path: "user-service"},
+			wantErr: "vault returned 403 for user-service: permission denied",
+		},
+		{
+			name:    "unknown path",
+			cfg:     Vault{Address: server.URL, Token: "dev-root", Mount: "secret", Path: "other"},
+			wantErr: "vault returned 404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewVaultSecretProvider(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewVaultSecretProvider: %v", err)
+			}
+
+			got, err := provider.Secrets(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Secrets: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("%s = %q, want %q", key, got[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestNewVaultSecretProviderRequiresAddressAndToken(t *testing.T) {
+	if _, err := NewVaultSecretProvider(Vault{Token: "dev-root"}); err == nil {
+		t.Error("no error without an address")
+	}
+	if _, err := NewVaultSecretProvider(Vault{Address: "http://127.0.0.1:8200"}); err == nil {
+		t.Error("no error without a token")
+	}
+}
+
+func TestSecretWatcherAppliesRotatedSecrets(t *testing.T) {
+	vault, server := newFakeVault(t)
+	vault.set("user-service", map[string]interface{}{"JWT_SECRET_KEY": "old", "DATABASE_PASSWORD": "pg-pass"})
+
+	provider, err := NewVaultSecretProvider(Vault{Address: server.URL, Token: "dev-root", Mount: "secret", Path: "user-service"})
+	if err != nil {
+		t.Fatalf("NewVaultSecretProvider: %v", err)
+	}
+	current, err := provider.Secrets(context.Background())
+	if err != nil {
+		t.Fatalf("Secrets: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	applied := make(chan string, 1)
+	watcher := NewSecretWatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), provider, 10*time.Millisecond, current)
+	go watcher.Watch(ctx, func(ctx context.Context, key, value string) error {
+		applied <- key + "=" + value
+		return nil
+	})
+
+	vault.set("user-service", map[string]interface{}{"JWT_SECRET_KEY": "new", "DATABASE_PASSWORD": "pg-pass"})
+
+	select {
+	case got := <-applied:
+		if got != "JWT_SECRET_KEY=new" {
+			t.Errorf("applied %s, want only JWT_SECRET_KEY=new", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("rotation not applied")
+	}
+}
+
+func TestSecretWatcherRetriesFailedRotation(t *testing.T) {
+	vault, server := newFakeVault(t)
+	vault.set("user-service", map[string]interface{}{"DATABASE_PASSWORD": "new"})
+
+	provider, err := NewVaultSecretProvider(Vault{Address: server.URL, Token: "dev-root", Mount: "secret", Path: "user-service"})
+	if err != nil {
+		t.Fatalf("NewVaultSecretProvider: %v", err)
+	}
+	watcher := NewSecretWatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), provider, time.Minute, map[string]string{"DATABASE_PASSWORD": "old"})
+
+	attempts := 0
+	apply := func(ctx context.Context, key, value string) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("password authentication failed")
+		}
+		return nil
+	}
+
+	watcher.refresh(context.Background(), apply)
+	if err := watcher.Check(context.Background()); err == nil || !strings.Contains(err.Error(), "DATABASE_PASSWORD") {
+		t.Fatalf("Check = %v after a failed rotation, want it to name DATABASE_PASSWORD", err)
+	}
+
+	watcher.refresh(context.Background(), apply)
+	if attempts != 2 {
+		t.Fatalf("apply called %d times, want the failed rotation retried", attempts)
+	}
+	if err := watcher.Check(context.Background()); err != nil {
+		t.Errorf("Check = %v after the retry succeeded, want nil", err)
+	}
+
+	watcher.refresh(context.Background(), apply)
+	if attempts != 2 {
+		t.Errorf("apply called %d times, want an applied value left alone", attempts)
+	}
+}
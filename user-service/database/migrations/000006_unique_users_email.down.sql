@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_email_lower;
+
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
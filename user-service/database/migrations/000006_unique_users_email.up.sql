@@ -0,0 +1,24 @@
+-- emails differing only in case must be merged or changed by hand first;
+-- fail with the colliding addresses rather than pick an account to drop
+DO $$
+DECLARE
+    collisions TEXT;
+BEGIN
+    SELECT string_agg(format('%s (user ids %s)', email, ids), ', ')
+    INTO collisions
+    FROM (
+        SELECT lower(email) AS email, string_agg(id::TEXT, ', ' ORDER BY id) AS ids
+        FROM users
+        GROUP BY lower(email)
+        HAVING count(*) > 1
+    ) duplicated;
+
+    IF collisions IS NOT NULL THEN
+        RAISE EXCEPTION 'users share an email ignoring case, resolve them before migrating: %', collisions;
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_users_email;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users(lower(email));
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS seed_runs (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    ran_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_seed_runs_name ON seed_runs(name);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_verification_tokens_user_id_token_type;
+DROP INDEX IF EXISTS idx_verification_tokens_token;
+
+-- hashes cannot be turned back into tokens, so outstanding tokens stop working
+DELETE FROM verification_tokens;
+
+ALTER TABLE verification_tokens DROP COLUMN IF EXISTS consumed_at;
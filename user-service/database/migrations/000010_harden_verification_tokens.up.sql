@@ -0,0 +1,7 @@
+ALTER TABLE verification_tokens ADD COLUMN IF NOT EXISTS consumed_at TIMESTAMP NULL;
+
+-- tokens were stored in plain text; keep outstanding ones usable by hashing them in place
+UPDATE verification_tokens SET token = encode(sha256(convert_to(token, 'UTF8')), 'hex');
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_verification_tokens_token ON verification_tokens(token);
+CREATE INDEX IF NOT EXISTS idx_verification_tokens_user_id_token_type ON verification_tokens(user_id, token_type);
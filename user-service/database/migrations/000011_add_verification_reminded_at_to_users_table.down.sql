@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_created_at_unverified;
+
+ALTER TABLE users DROP COLUMN IF EXISTS created_by_operator;
+ALTER TABLE users DROP COLUMN IF EXISTS verification_remind_attempted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS verification_reminded_at;
@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_reminded_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_remind_attempted_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS created_by_operator BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- sign up only creates customers, so any other role was given by an operator
+UPDATE users SET created_by_operator = TRUE
+WHERE id IN (
+    SELECT user_role.user_id FROM user_role
+    JOIN roles ON roles.id = user_role.role_id
+    WHERE roles.name <> 'Customer'
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_created_at_unverified ON users(created_at) WHERE is_verified = FALSE;
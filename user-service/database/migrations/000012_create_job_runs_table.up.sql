@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS job_runs (
+    name VARCHAR(100) PRIMARY KEY,
+    run_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,159 @@
+// Package migrations embeds the SQL migrations and runs them against the
+// configured database.
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+	"user-service/config"
+
+	"github.com/golang-migrate/migrate/v4"
+	// registers the pgx5:// database driver
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// lockTimeout bounds how long a migration waits for another instance holding
+// the migration lock.
+const lockTimeout = 2 * time.Minute
+
+// Migrator applies the embedded migrations. The postgres driver holds a
+// session advisory lock while migrating, so pods starting together run the
+// migrations once and the others wait for it.
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+// Status describes the schema version of the database.
+type Status struct {
+	Version uint
+	Dirty   bool
+	// Latest is the highest embedded migration version.
+	Latest uint
+	// Applied is false when no migration ever ran.
+	Applied bool
+}
+
+// New opens a migrator for the configured postgres database.
+func New(cfg *config.Config, log *slog.Logger) (*Migrator, error) {
+	source, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read embedded migrations: %w", err)
+	}
+
+	databaseURL, err := url.Parse(cfg.PostgresURL())
+	if err != nil {
+		return nil, fmt.Errorf("parse database url: %w", err)
+	}
+	databaseURL.Scheme = "pgx5"
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, databaseURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("open migration database: %w", err)
+	}
+	m.LockTimeout = lockTimeout
+	m.Log = migrateLogger{log: log}
+
+	return &Migrator{m: m}, nil
+}
+
+// Up applies every pending migration.
+func (mg *Migrator) Up() error {
+	return ignoreNoChange(mg.m.Up())
+}
+
+// Down rolls back the last steps migrations.
+func (mg *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1, got %d", steps)
+	}
+	return ignoreNoChange(mg.m.Steps(-steps))
+}
+
+// Goto migrates up or down to version.
+func (mg *Migrator) Goto(version uint) error {
+	return ignoreNoChange(mg.m.Migrate(version))
+}
+
+// Force records version as applied and clears the dirty flag without running
+// anything. It is the way out after a migration failed halfway and the
+// schema was repaired by hand.
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+// Status returns the current and latest versions.
+func (mg *Migrator) Status() (Status, error) {
+	status := Status{}
+
+	latest, err := latestVersion()
+	if err != nil {
+		return status, err
+	}
+	status.Latest = latest
+
+	version, dirty, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+
+	status.Version, status.Dirty, status.Applied = version, dirty, true
+	return status, nil
+}
+
+// Close releases the database connection.
+func (mg *Migrator) Close() error {
+	sourceErr, dbErr := mg.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+func latestVersion() (uint, error) {
+	source, err := iofs.New(files, ".")
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := source.Next(version)
+		if err != nil {
+			// fs.ErrNotExist past the last migration
+			return version, nil
+		}
+		version = next
+	}
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+type migrateLogger struct {
+	log *slog.Logger
+}
+
+func (l migrateLogger) Printf(format string, v ...interface{}) {
+	l.log.Info(strings.TrimSpace(fmt.Sprintf(format, v...)), "component", "migrate")
+}
+
+func (l migrateLogger) Verbose() bool {
+	return false
+}
@@ -1,37 +1,79 @@
 package seeds
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"user-service/internal/core/domain/entity"
 	"user-service/internal/core/domain/model"
 	"user-service/utils/conv"
+	"user-service/utils/password"
 
 	"gorm.io/gorm"
 )
 
-func SeedAdmin(db *gorm.DB) {
-	bytes, err := conv.HashPassword("admin123")
+// AdminOptions describes the super admin account. An empty Password is
+// replaced by a generated one, printed once when the account is created.
+type AdminOptions struct {
+	Name     string
+	Email    string
+	Password string
+}
+
+func adminSeed(opts AdminOptions, hasher *password.Hasher, out io.Writer) func(tx *gorm.DB, log *slog.Logger) error {
+	return func(tx *gorm.DB, log *slog.Logger) error {
+		return SeedAdmin(tx, log, opts, hasher, out)
+	}
+}
+
+// SeedAdmin creates the super admin unless a user with its email exists.
+func SeedAdmin(db *gorm.DB, log *slog.Logger, opts AdminOptions, hasher *password.Hasher, out io.Writer) error {
+	existing := model.User{}
+	err := db.Where("email = ?", opts.Email).First(&existing).Error
+	if err == nil {
+		log.Info("admin already exists", "email", opts.Email)
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to find admin: %w", err)
+	}
+
+	password, generated := opts.Password, false
+	if password == "" {
+		password, err = conv.GeneratePassword()
+		if err != nil {
+			return fmt.Errorf("failed to generate admin password: %w", err)
+		}
+		generated = true
+	}
+
+	bytes, err := hasher.Hash(password)
 	if err != nil {
-		log.Fatalf("%s: %v", "failed to hash password", err)
+		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	modelRole := model.Role{}
-	err = db.Where("name = ?", "Super Admin").First(&modelRole).Error
+	err = db.Where("name = ?", entity.RoleSuperAdmin).First(&modelRole).Error
 	if err != nil {
-		log.Fatalf("%s: %v", err.Error(), err)
+		return fmt.Errorf("failed to find role Super Admin: %w", err)
 	}
 
 	admin := model.User{
-		Name:       "super admin",
-		Email:      "superadmin@gmail.com",
+		Name:       opts.Name,
+		Email:      opts.Email,
 		Password:   bytes,
 		IsVerified: true,
 		Roles:      []model.Role{modelRole},
 	}
 
-	if err := db.FirstOrCreate(&admin, model.User{Email: admin.Email}).Error; err != nil {
-		log.Fatalf("%s: %v", err.Error(), err)
-	} else {
-		log.Printf("Admin %s created", admin.Name)
+	if err := db.Create(&admin).Error; err != nil {
+		return fmt.Errorf("failed to seed admin: %w", err)
 	}
+	log.Info("admin seeded", "name", admin.Name, "email", admin.Email)
 
+	if generated {
+		fmt.Fprintf(out, "super admin created\n  email:    %s\n  password: %s\nthe password is not stored anywhere else, keep it now\n", opts.Email, password)
+	}
+	return nil
 }
@@ -0,0 +1,102 @@
+package seeds
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/model"
+	"user-service/utils/i18n"
+	"user-service/utils/password"
+
+	"gorm.io/gorm"
+)
+
+// DemoPassword is the password of every demo customer.
+const DemoPassword = "sayur12345"
+
+var (
+	demoFirstNames = []string{
+		"Budi", "Siti", "Agus", "Dewi", "Rizky", "Putri", "Andi", "Ayu", "Fajar", "Intan",
+		"Hendra", "Rina", "Yusuf", "Maya", "Dimas", "Lestari", "Bayu", "Nur", "Eko", "Wulan",
+	}
+	demoLastNames = []string{
+		"Santoso", "Wijaya", "Saputra", "Lestari", "Pratama", "Hidayat", "Kusuma", "Nugroho",
+		"Siregar", "Simanjuntak", "Wibowo", "Rahmawati", "Setiawan", "Permata", "Harahap",
+	}
+	demoStreets = []string{
+		"Jl. Merdeka", "Jl. Sudirman", "Jl. Diponegoro", "Jl. Gatot Subroto", "Jl. Ahmad Yani",
+		"Jl. Pahlawan", "Jl. Melati", "Jl. Kenanga", "Jl. Cempaka", "Jl. Anggrek",
+	}
+	demoCities = []struct {
+		name     string
+		lat, lng float64
+	}{
+		{"Jakarta Selatan", -6.2615, 106.8106},
+		{"Jakarta Timur", -6.2250, 106.9004},
+		{"Bandung", -6.9175, 107.6191},
+		{"Surabaya", -7.2575, 112.7521},
+		{"Yogyakarta", -7.7956, 110.3695},
+		{"Depok", -6.4025, 106.7942},
+	}
+)
+
+func demoCustomersSeed(count int, hasher *password.Hasher, out io.Writer) func(tx *gorm.DB, log *slog.Logger) error {
+	return func(tx *gorm.DB, log *slog.Logger) error {
+		return SeedDemoCustomers(tx, log, count, hasher, out)
+	}
+}
+
+// SeedDemoCustomers creates count verified customers with realistic
+// Indonesian names, phone numbers and addresses. The data is the same on
+// every run, so existing customers are left alone.
+func SeedDemoCustomers(db *gorm.DB, log *slog.Logger, count int, hasher *password.Hasher, out io.Writer) error {
+	modelRole := model.Role{}
+	if err := db.Where("name = ?", entity.RoleCustomer).First(&modelRole).Error; err != nil {
+		return fmt.Errorf("failed to find role Customer: %w", err)
+	}
+
+	// hashing is slow on purpose, so every customer shares one hash
+	password, err := hasher.Hash(DemoPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	rnd := rand.New(rand.NewPCG(2024, 11))
+	created := 0
+	for i := 0; i < count; i++ {
+		first := demoFirstNames[rnd.IntN(len(demoFirstNames))]
+		last := demoLastNames[rnd.IntN(len(demoLastNames))]
+		city := demoCities[rnd.IntN(len(demoCities))]
+
+		language := i18n.Indonesian
+		if rnd.IntN(5) == 0 {
+			language = i18n.English
+		}
+
+		customer := model.User{
+			Name:       first + " " + last,
+			Email:      fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i+1),
+			Password:   password,
+			Phone:      fmt.Sprintf("+62812%08d", rnd.IntN(100000000)),
+			Address:    fmt.Sprintf("%s No. %d, %s", demoStreets[rnd.IntN(len(demoStreets))], rnd.IntN(200)+1, city.name),
+			Lat:        fmt.Sprintf("%.6f", city.lat+(rnd.Float64()-0.5)*0.1),
+			Lng:        fmt.Sprintf("%.6f", city.lng+(rnd.Float64()-0.5)*0.1),
+			IsVerified: true,
+			Language:   language,
+			Roles:      []model.Role{modelRole},
+		}
+
+		result := db.Where(model.User{Email: customer.Email}).FirstOrCreate(&customer)
+		if result.Error != nil {
+			return fmt.Errorf("failed to seed customer %s: %w", customer.Email, result.Error)
+		}
+		created += int(result.RowsAffected)
+	}
+
+	log.Info("demo customers seeded", "created", created, "total", count)
+	fmt.Fprintf(out, "%d demo customers created, all with password %s\n", created, DemoPassword)
+	return nil
+}
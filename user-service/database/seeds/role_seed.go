@@ -1,23 +1,25 @@
 package seeds
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
+	"user-service/internal/core/domain/entity"
 	"user-service/internal/core/domain/model"
 
 	"gorm.io/gorm"
 )
 
-func SeedRole(db *gorm.DB) {
+func SeedRole(db *gorm.DB, log *slog.Logger) error {
 	roles := []model.Role{
-		{Name: "Super Admin"},
-		{Name: "Customer"},
+		{Name: entity.RoleSuperAdmin},
+		{Name: entity.RoleCustomer},
 	}
 
 	for _, role := range roles {
 		if err := db.FirstOrCreate(&role, model.Role{Name: role.Name}).Error; err != nil {
-			log.Fatalf("%s: %v", err.Error(), err)
-		} else {
-			log.Printf("Role %s created", role.Name)
+			return fmt.Errorf("failed to seed role %s: %w", role.Name, err)
 		}
+		log.Info("role seeded", "role", role.Name)
 	}
+	return nil
 }
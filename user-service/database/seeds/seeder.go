@@ -0,0 +1,96 @@
+package seeds
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+	"user-service/utils/password"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SetBase = "base"
+	SetDemo = "demo"
+)
+
+// Seed is one unit of seed data. Once it ran it is recorded in seed_runs and
+// skipped by later runs.
+type Seed struct {
+	Name string
+	Run  func(tx *gorm.DB, log *slog.Logger) error
+}
+
+// Options configures the seed sets.
+type Options struct {
+	Admin AdminOptions
+	// Hasher hashes the passwords of seeded users.
+	Hasher *password.Hasher
+	// DemoCustomers is the number of fake customers of the demo set.
+	DemoCustomers int
+	// Out receives what an operator has to see exactly once, like a
+	// generated admin password. It is not the log.
+	Out io.Writer
+}
+
+// Sets returns the named seed sets. base holds what every environment needs;
+// demo adds fake data for local development on top of it.
+func Sets(opts Options) map[string][]Seed {
+	base := []Seed{
+		{Name: "roles", Run: SeedRole},
+		{Name: "admin", Run: adminSeed(opts.Admin, opts.Hasher, opts.Out)},
+	}
+
+	demo := append([]Seed{}, base...)
+	demo = append(demo, Seed{
+		Name: "demo_customers",
+		Run:  demoCustomersSeed(opts.DemoCustomers, opts.Hasher, opts.Out),
+	})
+
+	return map[string][]Seed{
+		SetBase: base,
+		SetDemo: demo,
+	}
+}
+
+type seedRun struct {
+	ID    int64 `gorm:"primaryKey"`
+	Name  string
+	RanAt time.Time
+}
+
+func (seedRun) TableName() string {
+	return "seed_runs"
+}
+
+// Run applies every seed not recorded yet, each in its own transaction, and
+// records it. With force, recorded seeds run again.
+func Run(db *gorm.DB, log *slog.Logger, seeds []Seed, force bool) error {
+	for _, seed := range seeds {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			run := seedRun{}
+			err := tx.Where("name = ?", seed.Name).First(&run).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err == nil && !force {
+				log.Info("seed already applied", "seed", seed.Name, "ran_at", run.RanAt)
+				return nil
+			}
+
+			if err := seed.Run(tx, log); err != nil {
+				return err
+			}
+
+			run.Name = seed.Name
+			run.RanAt = time.Now()
+			return tx.Save(&run).Error
+		})
+		if err != nil {
+			return fmt.Errorf("seed %s: %w", seed.Name, err)
+		}
+	}
+	return nil
+}
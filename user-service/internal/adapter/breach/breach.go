@@ -0,0 +1,29 @@
+package breach
+
+import (
+	"fmt"
+	"log/slog"
+	"user-service/config"
+	"user-service/internal/core/port"
+)
+
+const (
+	ProviderNone  = "none"
+	ProviderLocal = "local"
+	ProviderHIBP  = "hibp"
+)
+
+// NewProvider returns the breached password provider selected by
+// PASSWORD_BREACH_PROVIDER, or nil when the check is turned off.
+func NewProvider(cfg *config.Config, log *slog.Logger) (port.BreachedPasswordProviderInterface, error) {
+	switch cfg.Password.BreachProvider {
+	case "", ProviderNone:
+		return nil, nil
+	case ProviderLocal:
+		return NewLocalProvider(cfg.Password.BreachListFile, log)
+	case ProviderHIBP:
+		return NewHIBPProvider(cfg.Password.BreachAPIURL, cfg.Password.BreachTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown breached password provider %q", cfg.Password.BreachProvider)
+	}
+}
@@ -0,0 +1,59 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HIBPProvider asks the Have I Been Pwned range API. Responses are padded
+// with fake entries so their size does not hint at the prefix.
+type HIBPProvider struct {
+	url    string
+	client *http.Client
+}
+
+// Range implements port.BreachedPasswordProviderInterface.
+func (h *HIBPProvider) Range(ctx context.Context, prefix string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url+"/range/"+prefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "sayur-user-service")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("breached password range %s: unexpected status %d", prefix, resp.StatusCode)
+	}
+
+	var suffixes []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		suffix, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		// padding entries have a zero count
+		if suffix == "" || count == "0" {
+			continue
+		}
+		suffixes = append(suffixes, strings.ToUpper(suffix))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read breached password range %s: %w", prefix, err)
+	}
+	return suffixes, nil
+}
+
+func NewHIBPProvider(url string, timeout time.Duration) *HIBPProvider {
+	return &HIBPProvider{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: timeout},
+	}
+}
@@ -0,0 +1,54 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// LocalProvider answers from a list of breached password hashes loaded in
+// memory, for deployments that must not call out. The list holds one upper
+// or lower case hex SHA-1 hash per line, optionally followed by :count as in
+// the Have I Been Pwned downloads.
+type LocalProvider struct {
+	suffixes map[string][]string
+}
+
+// Range implements port.BreachedPasswordProviderInterface.
+func (l *LocalProvider) Range(ctx context.Context, prefix string) ([]string, error) {
+	return l.suffixes[strings.ToUpper(prefix)], nil
+}
+
+func NewLocalProvider(file string, log *slog.Logger) (*LocalProvider, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	provider := &LocalProvider{suffixes: map[string][]string{}}
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if hash == "" || strings.HasPrefix(hash, "#") {
+			continue
+		}
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != 40 {
+			return nil, fmt.Errorf("breached password list %s line %d: not a SHA-1 hash", file, line)
+		}
+		hash = strings.ToUpper(hash)
+		provider.suffixes[hash[:5]] = append(provider.suffixes[hash[:5]], hash[5:])
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read breached password list %s: %w", file, err)
+	}
+
+	log.Info("breached password list loaded", "file", file, "count", count)
+	return provider, nil
+}
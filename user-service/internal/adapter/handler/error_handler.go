@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"user-service/internal/adapter/handler/response"
+	"user-service/internal/core/domain/errs"
+	"user-service/utils/i18n"
+	"user-service/utils/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// MIMEProblemJSON is the RFC 7807 media type of error responses.
+	MIMEProblemJSON = "application/problem+json"
+
+	// problemTypePrefix prefixes the error code to build the problem type URI.
+	problemTypePrefix = "urn:sayur:problem:"
+)
+
+// statusByCode maps domain error codes to HTTP statuses. Unknown codes are
+// treated as internal errors.
+var statusByCode = map[errs.Code]int{
+	errs.CodeBadRequest:         http.StatusBadRequest,
+	errs.CodeValidation:         http.StatusUnprocessableEntity,
+	errs.CodeNotFound:           http.StatusNotFound,
+	errs.CodeUserNotFound:       http.StatusNotFound,
+	errs.CodeUnauthorized:       http.StatusUnauthorized,
+	errs.CodeInvalidCredentials: http.StatusUnauthorized,
+	errs.CodeTokenMissing:       http.StatusUnauthorized,
+	errs.CodeTokenInvalid:       http.StatusUnauthorized,
+	errs.CodeTokenExpired:       http.StatusUnauthorized,
+	errs.CodeSessionNotFound:    http.StatusUnauthorized,
+	errs.CodeForbidden:          http.StatusForbidden,
+	errs.CodeAccountSuspended:   http.StatusForbidden,
+	errs.CodeAccountUnverified:  http.StatusForbidden,
+	errs.CodeRoleNotFound:       http.StatusNotFound,
+	errs.CodeConflict:           http.StatusConflict,
+	errs.CodeEmailTaken:         http.StatusConflict,
+	errs.CodeTooManyRequests:    http.StatusTooManyRequests,
+	errs.CodeWeakPassword:       http.StatusUnprocessableEntity,
+	errs.CodeUnavailable:        http.StatusServiceUnavailable,
+	errs.CodeInternal:           http.StatusInternalServerError,
+}
+
+// codeByStatus is used for errors raised by echo itself (bind errors,
+// unknown routes, ...).
+var codeByStatus = map[int]errs.Code{
+	http.StatusBadRequest:          errs.CodeBadRequest,
+	http.StatusUnauthorized:        errs.CodeUnauthorized,
+	http.StatusForbidden:           errs.CodeForbidden,
+	http.StatusNotFound:            errs.CodeNotFound,
+	http.StatusConflict:            errs.CodeConflict,
+	http.StatusUnprocessableEntity: errs.CodeValidation,
+	http.StatusTooManyRequests:     errs.CodeTooManyRequests,
+	http.StatusServiceUnavailable:  errs.CodeUnavailable,
+}
+
+// NewErrorHandler returns the echo HTTPErrorHandler that turns every error
+// returned by a handler or middleware into a problem+json response. Only
+// domain error messages reach the client, localised to the request
+// language; anything else becomes internal_error.
+func NewErrorHandler(log *slog.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		ctx := c.Request().Context()
+		problem := newProblem(err, i18n.Language(ctx))
+		problem.Instance = c.Request().URL.Path
+		problem.RequestID = logger.RequestID(ctx)
+		if problem.Status >= http.StatusInternalServerError {
+			log.ErrorContext(ctx, "request failed", "route", c.Path(), "error", err)
+		}
+
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(problem.Status)
+		} else {
+			c.Response().Header().Set(echo.HeaderContentType, MIMEProblemJSON)
+			err = c.JSON(problem.Status, problem)
+		}
+		if err != nil {
+			log.ErrorContext(ctx, "failed to write error response", "error", err)
+		}
+	}
+}
+
+func newProblem(err error, lang string) response.ProblemResponse {
+	var (
+		domainErr *errs.Error
+		httpErr   *echo.HTTPError
+	)
+
+	switch {
+	case errors.As(err, &domainErr):
+		status, ok := statusByCode[domainErr.Code]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		detail := domainErr.Message
+		if key := "error." + string(domainErr.Code); i18n.Has(key) {
+			detail = i18n.T(lang, key)
+		}
+		problem := problemFor(status, domainErr.Code, detail)
+		for _, field := range domainErr.Fields {
+			problem.Errors = append(problem.Errors, response.FieldErrorResponse(field))
+		}
+		return problem
+	case errors.As(err, &httpErr):
+		code, ok := codeByStatus[httpErr.Code]
+		if !ok {
+			code = errs.CodeInternal
+			if httpErr.Code < http.StatusInternalServerError {
+				code = errs.CodeBadRequest
+			}
+		}
+		message, ok := httpErr.Message.(string)
+		if !ok {
+			message = http.StatusText(httpErr.Code)
+		}
+		return problemFor(httpErr.Code, code, message)
+	default:
+		return problemFor(http.StatusInternalServerError, errs.CodeInternal, i18n.T(lang, "error.internal_error"))
+	}
+}
+
+func problemFor(status int, code errs.Code, detail string) response.ProblemResponse {
+	return response.ProblemResponse{
+		Type:   problemTypePrefix + string(code),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   string(code),
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"user-service/internal/adapter/handler/response"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HealthHandlerInterface interface {
+	Liveness(c echo.Context) error
+	Readiness(c echo.Context) error
+}
+
+type healthHandler struct {
+	log           *slog.Logger
+	healthService service.HealthServiceInterface
+}
+
+// Liveness implements HealthHandlerInterface.
+func (h *healthHandler) Liveness(c echo.Context) error {
+	return h.healthJSON(c, h.healthService.Liveness(c.Request().Context()))
+}
+
+// Readiness implements HealthHandlerInterface.
+func (h *healthHandler) Readiness(c echo.Context) error {
+	return h.healthJSON(c, h.healthService.Readiness(c.Request().Context()))
+}
+
+// healthJSON writes health without the check errors, which may name internal
+// hosts or credentials; they are logged instead.
+func (h *healthHandler) healthJSON(c echo.Context, health entity.HealthEntity) error {
+	resp := response.HealthResponse{Status: health.Status}
+	for _, check := range health.Checks {
+		if check.Error != "" {
+			h.log.WarnContext(c.Request().Context(), "health check failed", "route", c.Path(), "check", check.Name, "error", check.Error)
+		}
+		resp.Checks = append(resp.Checks, response.HealthCheckResponse{
+			Name:       check.Name,
+			Status:     check.Status,
+			DurationMs: float64(check.Duration.Microseconds()) / 1000,
+			CheckedAt:  check.CheckedAt,
+		})
+	}
+
+	status := http.StatusOK
+	if health.Status != entity.HealthStatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, resp)
+}
+
+func NewHealthHandler(e *echo.Echo, log *slog.Logger, healthService service.HealthServiceInterface) HealthHandlerInterface {
+	healthHandler := &healthHandler{log: log, healthService: healthService}
+
+	e.GET("/healthz", healthHandler.Liveness)
+	e.GET("/readyz", healthHandler.Readiness)
+
+	return healthHandler
+}
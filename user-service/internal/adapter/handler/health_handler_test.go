@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"user-service/internal/core/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestReadinessHidesCheckErrors(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+
+	e := echo.New()
+	NewHealthHandler(e, log, service.NewHealthService(time.Second, 0,
+		service.HealthCheck{Name: "postgres", Check: func(ctx context.Context) error {
+			return errors.New("dial tcp 10.0.3.7:5432: password authentication failed for user \"sayur\"")
+		}},
+	))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `"name":"postgres"`) || !strings.Contains(body, `"status":"down"`) {
+		t.Errorf("body = %s, want the postgres check reported down", body)
+	}
+	if strings.Contains(body, "10.0.3.7") || strings.Contains(body, "error") {
+		t.Errorf("body = %s, leaks the check error", body)
+	}
+	if !strings.Contains(logs.String(), "10.0.3.7") {
+		t.Errorf("check error not logged: %s", logs.String())
+	}
+}
@@ -0,0 +1,150 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"user-service/internal/adapter/handler/request"
+	"user-service/internal/adapter/handler/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// requestSchemas and responseSchemas map spec schema names to the structs
+// they describe. A request field is required when it is validated as
+// required, a response field when it is not omitempty.
+var (
+	requestSchemas = map[string]any{
+		"SignInRequest":             request.SignInRequest{},
+		"SignUpRequest":             request.SignUpRequest{},
+		"ForgotPasswordRequest":     request.ForgotPasswordRequest{},
+		"ResendVerificationRequest": request.ResendVerificationRequest{},
+		"UpdatePasswordRequest":     request.UpdatePasswordRequest{},
+	}
+	responseSchemas = map[string]any{
+		"DefaultResponse":    response.DefaultResponse{},
+		"SignInResponse":     response.SignInResponse{},
+		"ProblemResponse":    response.ProblemResponse{},
+		"FieldErrorResponse": response.FieldErrorResponse{},
+	}
+)
+
+// docsPaths are served next to the API by Register and are not part of it.
+var docsPaths = []string{"/openapi.yaml", "/docs"}
+
+var methods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	http.MethodHead, http.MethodOptions,
+}
+
+// Check reports every difference between the spec and the routes registered
+// under prefix, and between the spec schemas and the request and response
+// structs. It returns nil when they agree.
+func Check(doc *Document, routes []*echo.Route, prefix string) error {
+	var problems []string
+
+	if len(doc.Servers) == 0 || doc.Servers[0].URL != prefix {
+		problems = append(problems, fmt.Sprintf("spec server url must be %s", prefix))
+	}
+
+	inSpec := map[string]bool{}
+	for path, item := range doc.Paths {
+		for method := range item {
+			method = strings.ToUpper(method)
+			if slices.Contains(methods, method) {
+				inSpec[method+" "+path] = true
+			}
+		}
+	}
+
+	inCode := map[string]bool{}
+	for _, route := range routes {
+		path, ok := strings.CutPrefix(route.Path, prefix)
+		if !ok || !slices.Contains(methods, route.Method) || slices.Contains(docsPaths, path) {
+			continue
+		}
+		inCode[route.Method+" "+specPath(path)] = true
+	}
+
+	for op := range inCode {
+		if !inSpec[op] {
+			problems = append(problems, fmt.Sprintf("%s is routed but not in the spec", op))
+		}
+	}
+	for op := range inSpec {
+		if !inCode[op] {
+			problems = append(problems, fmt.Sprintf("%s is in the spec but not routed", op))
+		}
+	}
+
+	for name, v := range requestSchemas {
+		problems = append(problems, checkSchema(doc, name, reflect.TypeOf(v), requiredByValidation)...)
+	}
+	for name, v := range responseSchemas {
+		problems = append(problems, checkSchema(doc, name, reflect.TypeOf(v), requiredUnlessOmitempty)...)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return errors.New("openapi spec out of date:\n  " + strings.Join(problems, "\n  "))
+}
+
+func checkSchema(doc *Document, name string, t reflect.Type, required func(reflect.StructField) bool) []string {
+	schema, ok := doc.Components.Schemas[name]
+	if !ok {
+		return []string{fmt.Sprintf("schema %s is missing", name)}
+	}
+
+	var problems []string
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonName := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if jsonName == "-" || !field.IsExported() {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		fields[jsonName] = true
+
+		if _, ok := schema.Properties[jsonName]; !ok {
+			problems = append(problems, fmt.Sprintf("schema %s lacks property %s", name, jsonName))
+		}
+		if required(field) != slices.Contains(schema.Required, jsonName) {
+			problems = append(problems, fmt.Sprintf("schema %s: required of %s does not match %s", name, jsonName, t.Name()))
+		}
+	}
+
+	for property := range schema.Properties {
+		if !fields[property] {
+			problems = append(problems, fmt.Sprintf("schema %s has property %s that %s does not", name, property, t.Name()))
+		}
+	}
+	return problems
+}
+
+func requiredByValidation(field reflect.StructField) bool {
+	return slices.Contains(strings.Split(field.Tag.Get("validate"), ","), "required")
+}
+
+func requiredUnlessOmitempty(field reflect.StructField) bool {
+	return !slices.Contains(strings.Split(field.Tag.Get("json"), ",")[1:], "omitempty")
+}
+
+// specPath turns echo path params (:id) into OpenAPI ones ({id}).
+func specPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if param, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + param + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,47 @@
+package openapi_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"user-service/internal/adapter"
+	"user-service/internal/adapter/handler"
+	"user-service/internal/adapter/handler/openapi"
+
+	"github.com/labstack/echo/v4"
+)
+
+// routes registers the API the way the server does, without connecting to
+// anything; only the route table is inspected.
+func routes() *echo.Echo {
+	e := echo.New()
+	handler.NewUserHandler(e, nil, adapter.NewMiddlewareAdapter(nil, nil, nil))
+	openapi.Register(e, handler.APIPrefix, true)
+	return e
+}
+
+func TestSpecMatchesRoutes(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if err := openapi.Check(doc, routes().Routes(), handler.APIPrefix); err != nil {
+		t.Errorf("spec and code differ:\n%v", err)
+	}
+}
+
+func TestCheckReportsUndocumentedRoute(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	e := routes()
+	e.DELETE(handler.APIPrefix+"/admin/customers/:id", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+
+	err = openapi.Check(doc, e.Routes(), handler.APIPrefix)
+	if err == nil || !strings.Contains(err.Error(), "/admin/customers/{id}") {
+		t.Errorf("Check = %v, want it to report the undocumented route", err)
+	}
+}
@@ -0,0 +1,80 @@
+package openapi
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the OpenAPI 3 document of the versioned API. It is written by hand
+// and kept honest by Check.
+//
+//go:embed openapi.yaml
+var Spec []byte
+
+// Document is the part of the spec Check compares against the code.
+type Document struct {
+	OpenAPI string                          `yaml:"openapi"`
+	Servers []Server                        `yaml:"servers"`
+	Paths   map[string]map[string]yaml.Node `yaml:"paths"`
+
+	Components struct {
+		Schemas map[string]Schema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type Server struct {
+	URL string `yaml:"url"`
+}
+
+type Schema struct {
+	Type       string            `yaml:"type"`
+	Required   []string          `yaml:"required"`
+	Properties map[string]Schema `yaml:"properties"`
+}
+
+// Load parses Spec.
+func Load() (*Document, error) {
+	doc := &Document{}
+	if err := yaml.Unmarshal(Spec, doc); err != nil {
+		return nil, fmt.Errorf("parse openapi spec: %w", err)
+	}
+	return doc, nil
+}
+
+// Register serves the spec under prefix, and Swagger UI next to it when ui
+// is set.
+func Register(e *echo.Echo, prefix string, ui bool) {
+	e.GET(prefix+"/openapi.yaml", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, "application/yaml", Spec)
+	})
+	if !ui {
+		return
+	}
+
+	page := fmt.Sprintf(swaggerUI, prefix+"/openapi.yaml")
+	e.GET(prefix+"/docs", func(c echo.Context) error {
+		return c.HTML(http.StatusOK, page)
+	})
+}
+
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>micro-sayur user service</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function () {
+      SwaggerUIBundle({ url: %q, dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>`
@@ -9,15 +9,23 @@ type SignUpRequest struct {
 	Name                 string `json:"name" validate:"required"`
 	Email                string `json:"email" validate:"email,required"`
 	Password             string `json:"password" validate:"required,min=8"`
-	PasswordConfirmation string `json:"password_confirmation" validate:"required,min=8"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,eqfield=Password"`
+	Language             string `json:"language" validate:"omitempty,oneof=en id"`
+	Client               string `json:"client" validate:"omitempty,oneof=web android ios"`
 }
 
 type ForgotPasswordRequest struct {
-	Email string `json:"email" validate:"email,required"`
+	Email  string `json:"email" validate:"email,required"`
+	Client string `json:"client" validate:"omitempty,oneof=web android ios"`
+}
+
+type ResendVerificationRequest struct {
+	Email  string `json:"email" validate:"email,required"`
+	Client string `json:"client" validate:"omitempty,oneof=web android ios"`
 }
 
 type UpdatePasswordRequest struct {
 	CurrentPassword string `json:"password,omitempty"`
 	NewPassword     string `json:"password_new" validate:"required"`
-	ConfirmPassword string `json:"password_confirmation" validate:"required"`
+	ConfirmPassword string `json:"password_confirmation" validate:"required,eqfield=NewPassword"`
 }
@@ -1,17 +1,20 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
-	"user-service/config"
 	"user-service/internal/adapter"
 	"user-service/internal/adapter/handler/request"
 	"user-service/internal/adapter/handler/response"
+	"user-service/internal/adapter/metrics"
 	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/errs"
 	"user-service/internal/core/service"
+	"user-service/utils/i18n"
+	"user-service/utils/validator"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"github.com/labstack/gommon/log"
 )
 
 type UserHandlerInterface interface {
@@ -19,6 +22,7 @@ type UserHandlerInterface interface {
 	CreateUserAccount(ctx echo.Context) error
 	ForgotPassword(ctx echo.Context) error
 	VerifyAccount(ctx echo.Context) error
+	ResendVerification(ctx echo.Context) error
 	UpdatePassword(ctx echo.Context) error
 }
 
@@ -36,31 +40,15 @@ func (u *userHandler) UpdatePassword(c echo.Context) error {
 
 	tokenString := c.QueryParam("token")
 	if tokenString == "" {
-		log.Infof("[UserHandler-1] UpdatePassword: %s", "missing or invalid token")
-		resp.Message = "missing or invalid token"
-		resp.Data = nil
-		return c.JSON(http.StatusUnauthorized, resp)
+		return errs.ErrTokenMissing
 	}
 
 	if err := c.Bind(&req); err != nil {
-		log.Errorf("[UserHandler-2] UpdatePassword: %v", err)
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusBadRequest, resp)
+		return err
 	}
 
 	if err := c.Validate(&req); err != nil {
-		log.Errorf("[UserHandler-3] UpdatePassword: %v", err)
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusUnprocessableEntity, resp)
-	}
-
-	if req.NewPassword != req.ConfirmPassword {
-		log.Errorf("[UserHandler-4] UpdatePassword: %s", "new password and confirm password does not match")
-		resp.Message = "new password and confirm password does not match"
-		resp.Data = nil
-		return c.JSON(http.StatusUnprocessableEntity, resp)
+		return validationError(c, err)
 	}
 
 	reqEntity := entity.UserEntity{
@@ -68,25 +56,11 @@ func (u *userHandler) UpdatePassword(c echo.Context) error {
 		Token:    tokenString,
 	}
 
-	err := u.userService.UpdatePassword(ctx, reqEntity)
-	if err != nil {
-		log.Errorf("[UserHandler-5] UpdatePassword: %v", err)
-		if err.Error() == "404" {
-			resp.Message = "User not found"
-			resp.Data = nil
-			return c.JSON(http.StatusNotFound, resp)
-		}
-		if err.Error() == "401" {
-			resp.Message = "Token expired or Invalid"
-			resp.Data = nil
-			return c.JSON(http.StatusUnauthorized, resp)
-		}
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusInternalServerError, resp)
+	if err := u.userService.UpdatePassword(ctx, reqEntity); err != nil {
+		return passwordFieldError(err, "password_new")
 	}
 
-	resp.Message = "Password updated successfully"
+	resp.Message = i18n.T(i18n.Language(ctx), "password_updated")
 	resp.Data = nil
 	return c.JSON(http.StatusOK, resp)
 }
@@ -101,28 +75,12 @@ func (u *userHandler) VerifyAccount(c echo.Context) error {
 
 	tokenString := c.QueryParam("token")
 	if tokenString == "" {
-		log.Infof("[UserHandler-1] VerifyAccount: %s", "missing or invalid token")
-		resp.Message = "missing or invalid token"
-		resp.Data = nil
-		return c.JSON(http.StatusUnauthorized, resp)
+		return errs.ErrTokenMissing
 	}
 
 	user, err := u.userService.VerifyToken(ctx, tokenString)
 	if err != nil {
-		log.Errorf("[UserHandler-2] VerifyAccount: %v", err)
-		if err.Error() == "404" {
-			resp.Message = "User not found"
-			resp.Data = nil
-			return c.JSON(http.StatusNotFound, resp)
-		}
-		if err.Error() == "401" {
-			resp.Message = "Token expired or Invalid"
-			resp.Data = nil
-			return c.JSON(http.StatusUnauthorized, resp)
-		}
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusInternalServerError, resp)
+		return err
 	}
 
 	respSignIn.ID = user.ID
@@ -134,13 +92,45 @@ func (u *userHandler) VerifyAccount(c echo.Context) error {
 	respSignIn.Phone = user.Phone
 	respSignIn.AccessToken = user.Token
 
-	resp.Message = "Success"
+	resp.Message = i18n.T(i18n.Language(ctx), "success")
 	resp.Data = respSignIn
 
 	return c.JSON(http.StatusOK, resp)
 }
 
-// ForgotPassword implements UserHandlerInterface.
+// ResendVerification implements UserHandlerInterface. It answers the same
+// for every email, registered or not; each email is throttled alike.
+func (u *userHandler) ResendVerification(c echo.Context) error {
+	var (
+		req  = request.ResendVerificationRequest{}
+		resp = response.DefaultResponse{}
+		ctx  = c.Request().Context()
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+
+	reqEntity := entity.UserEntity{
+		Email:  req.Email,
+		Client: req.Client,
+	}
+
+	if err := u.userService.ResendVerification(ctx, reqEntity); err != nil {
+		return err
+	}
+
+	resp.Message = i18n.T(i18n.Language(ctx), "verification_sent")
+	resp.Data = nil
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ForgotPassword implements UserHandlerInterface. Like ResendVerification
+// it answers the same for every email, registered or not.
 func (u *userHandler) ForgotPassword(c echo.Context) error {
 	var (
 		req  = request.ForgotPasswordRequest{}
@@ -149,42 +139,28 @@ func (u *userHandler) ForgotPassword(c echo.Context) error {
 	)
 
 	if err := c.Bind(&req); err != nil {
-		log.Errorf("[UserHandler-1] ForgotPassword: %v", err)
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusUnprocessableEntity, resp)
+		return err
 	}
 
 	if err := c.Validate(&req); err != nil {
-		log.Errorf("[UserHandler-2] ForgotPassword: %v", err)
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusUnprocessableEntity, resp)
+		return validationError(c, err)
 	}
 
 	reqEntity := entity.UserEntity{
-		Email: req.Email,
+		Email:  req.Email,
+		Client: req.Client,
 	}
 
-	err := u.userService.ForgotPassword(ctx, reqEntity)
-	if err != nil {
-		log.Errorf("[UserHandler-3] ForgotPassword: %v", err)
-		if err.Error() == "404" {
-			resp.Message = "User not found"
-			resp.Data = nil
-			return c.JSON(http.StatusNotFound, resp)
-		}
-		log.Errorf("[UserHandler-3] ForgotPassword: %v", err)
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusInternalServerError, resp)
+	if err := u.userService.ForgotPassword(ctx, reqEntity); err != nil {
+		return err
 	}
 
-	resp.Message = "Success"
+	resp.Message = i18n.T(i18n.Language(ctx), "success")
 	resp.Data = nil
 	return c.JSON(http.StatusOK, resp)
 }
 
+// CreateUserAccount implements UserHandlerInterface.
 func (u *userHandler) CreateUserAccount(c echo.Context) error {
 	var (
 		req  = request.SignUpRequest{}
@@ -193,41 +169,26 @@ func (u *userHandler) CreateUserAccount(c echo.Context) error {
 	)
 
 	if err := c.Bind(&req); err != nil {
-		log.Errorf("[UserHandler-1] CreateUserAccount: %v", err)
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusUnprocessableEntity, resp)
+		return err
 	}
 
 	if err := c.Validate(&req); err != nil {
-		log.Errorf("[UserHandler-2] CreateUserAccount: %v", err)
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusUnprocessableEntity, resp)
-	}
-
-	if req.Password != req.PasswordConfirmation {
-		log.Errorf("[UserHandler-4] CreateUserAccount: %s", "Password not match")
-		resp.Message = "Password not match"
-		resp.Data = nil
-		return c.JSON(http.StatusUnprocessableEntity, resp)
+		return validationError(c, err)
 	}
 
 	reqEntity := entity.UserEntity{
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: req.Password,
+		Language: req.Language,
+		Client:   req.Client,
 	}
 
-	err := u.userService.CreateUserAccount(ctx, reqEntity)
-	if err != nil {
-		log.Errorf("[UserHandler-3] CreateUserAccount: %v", err)
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusInternalServerError, resp)
+	if err := u.userService.CreateUserAccount(ctx, reqEntity); err != nil {
+		return err
 	}
 
-	resp.Message = "Success"
+	resp.Message = i18n.T(i18n.Language(ctx), "success")
 	resp.Data = nil
 	return c.JSON(http.StatusCreated, resp)
 }
@@ -241,18 +202,12 @@ func (u *userHandler) SignIn(c echo.Context) error {
 		ctx        = c.Request().Context()
 	)
 
-	if err = c.Bind(&req); err != nil {
-		log.Errorf("[UserHandler-1] SignIn: %v", err)
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusUnprocessableEntity, resp)
+	if err := c.Bind(&req); err != nil {
+		return err
 	}
 
-	if err = c.Validate((req)); err != nil {
-		log.Errorf("[UserHandler-2] SignIn: %v", err)
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusUnprocessableEntity, resp)
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
 	}
 
 	reqEntity := entity.UserEntity{
@@ -262,16 +217,7 @@ func (u *userHandler) SignIn(c echo.Context) error {
 
 	user, token, err := u.userService.SignIn(ctx, reqEntity)
 	if err != nil {
-		if err.Error() == "404" {
-			log.Errorf("[UserHandler-3] SignIn: %v", "User Not Found")
-			resp.Message = "User not found"
-			resp.Data = nil
-			return c.JSON(http.StatusNotFound, resp)
-		}
-		log.Errorf("[UserHandler-3] SignIn: %v", err)
-		resp.Message = err.Error()
-		resp.Data = nil
-		return c.JSON(http.StatusUnauthorized, resp)
+		return err
 	}
 
 	respSignIn.ID = user.ID
@@ -283,30 +229,74 @@ func (u *userHandler) SignIn(c echo.Context) error {
 	respSignIn.Phone = user.Phone
 	respSignIn.AccessToken = token
 
-	resp.Message = "Success"
+	resp.Message = i18n.T(i18n.Language(ctx), "success")
 	resp.Data = respSignIn
 
 	return c.JSON(http.StatusOK, resp)
 }
 
-var err error
+// validationError reports a failed c.Validate as a domain validation error
+// carrying every failed field, with messages in the request language.
+func validationError(c echo.Context, err error) error {
+	var validationErr *validator.ValidationError
+	if !errors.As(err, &validationErr) {
+		return errs.Wrap(err, errs.CodeValidation, err.Error())
+	}
+
+	lang := i18n.Language(c.Request().Context())
+	fields := make([]errs.FieldError, 0, len(validationErr.Fields))
+	for _, field := range validationErr.Fields {
+		fields = append(fields, errs.FieldError{
+			Field:   field.Field,
+			Rule:    field.Rule,
+			Param:   field.Param,
+			Message: field.Message(lang),
+		})
+	}
+	return errs.Validation(fields...).WithCause(err)
+}
+
+// passwordFieldError points the fields of a weak password error at field, the
+// name the request carries the password under.
+func passwordFieldError(err error, field string) error {
+	var domainErr *errs.Error
+	if !errors.As(err, &domainErr) || domainErr.Code != errs.CodeWeakPassword {
+		return err
+	}
+
+	fields := make([]errs.FieldError, 0, len(domainErr.Fields))
+	for _, f := range domainErr.Fields {
+		f.Field = field
+		fields = append(fields, f)
+	}
+	return errs.WeakPassword(fields...)
+}
 
-func NewUserHandler(e *echo.Echo, userService service.UserServiceInterface, cfg *config.Config) UserHandlerInterface {
+func NewUserHandler(e *echo.Echo, userService service.UserServiceInterface, mid adapter.MiddlewareAdapterInterface) UserHandlerInterface {
 	userHandler := &userHandler{userService: userService}
 
 	e.Use(middleware.Recover())
-	e.POST("/signin", userHandler.SignIn)
-	e.POST("/signup", userHandler.CreateUserAccount)
-	e.POST("/forgot-password", userHandler.ForgotPassword)
-	e.GET("/verify-account", userHandler.VerifyAccount)
-	e.PUT("/update-password", userHandler.UpdatePassword)
-
-	mid := adapter.NewMiddlewareAdapter(cfg)
-	adminGroup := e.Group("/admin", mid.CheckToken())
-	adminGroup.GET("/check", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, "OK")
-	})
-	// e.Use(mid.CheckToken())
+	userHandler.routes(e.Group(APIPrefix), mid)
+	// unversioned paths from before /api/v1, kept until clients have moved
+	userHandler.routes(e.Group(""), mid, deprecatedAlias)
 
 	return userHandler
 }
+
+// routes registers the user routes on g, with m in front of every route.
+func (u *userHandler) routes(g *echo.Group, mid adapter.MiddlewareAdapterInterface, m ...echo.MiddlewareFunc) {
+	with := func(extra ...echo.MiddlewareFunc) []echo.MiddlewareFunc {
+		return append(append([]echo.MiddlewareFunc{}, m...), extra...)
+	}
+
+	g.POST("/signin", u.SignIn, with(metrics.TrackAuth("signin"))...)
+	g.POST("/signup", u.CreateUserAccount, with(metrics.TrackAuth("signup"))...)
+	g.POST("/forgot-password", u.ForgotPassword, with(metrics.TrackAuth("forgot_password"))...)
+	g.GET("/verify-account", u.VerifyAccount, with(metrics.TrackAuth("verify_account"))...)
+	g.POST("/verify-account/resend", u.ResendVerification, with(metrics.TrackAuth("resend_verification"))...)
+	g.PUT("/update-password", u.UpdatePassword, with(metrics.TrackAuth("password_reset"))...)
+
+	g.GET("/admin/check", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, "OK")
+	}, with(mid.CheckToken())...)
+}
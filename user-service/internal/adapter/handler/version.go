@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIPrefix is the path every versioned API route is registered under.
+const APIPrefix = "/api/v1"
+
+// deprecatedAlias marks responses of an unversioned route as deprecated and
+// points clients at the same route under APIPrefix.
+func deprecatedAlias(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		header := c.Response().Header()
+		header.Set("Deprecation", "true")
+		header.Set("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, APIPrefix, c.Path()))
+		return next(c)
+	}
+}
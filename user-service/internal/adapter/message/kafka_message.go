@@ -0,0 +1,100 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"user-service/config"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/port"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+)
+
+type kafkaPublisher struct {
+	log         *slog.Logger
+	writer      *kafka.Writer
+	brokers     []string
+	topicPrefix string
+}
+
+// Publish implements port.PublisherInterface.
+func (k *kafkaPublisher) Publish(ctx context.Context, notification entity.NotificationEntity) (err error) {
+	topic := k.topicPrefix + notification.NotificationType
+	ctx, span := startPublishSpan(ctx, BrokerKafka, topic)
+	defer func() { endPublishSpan(span, err) }()
+
+	message, err := k.message(ctx, topic, notification)
+	if err != nil {
+		k.log.ErrorContext(ctx, "failed to marshal notification", "op", "Publish", "error", err)
+		return err
+	}
+
+	err = k.writer.WriteMessages(ctx, message)
+	if err != nil {
+		k.log.ErrorContext(ctx, "failed to write message", "op", "Publish", "topic", topic, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// message builds the record of notification for topic, keyed by email so
+// the notifications of one user stay in order, with the trace context of ctx
+// in its headers.
+func (k *kafkaPublisher) message(ctx context.Context, topic string, notification entity.NotificationEntity) (kafka.Message, error) {
+	body, err := encodeNotification(notification)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	headers := []kafka.Header{
+		{Key: "content-type", Value: []byte("application/json")},
+	}
+	otel.GetTextMapPropagator().Inject(ctx, kafkaHeaderCarrier{headers: &headers})
+
+	return kafka.Message{
+		Topic:   topic,
+		Key:     []byte(notification.Email),
+		Value:   body,
+		Headers: headers,
+	}, nil
+}
+
+// Ping implements port.PublisherInterface. It succeeds as soon as one broker
+// accepts a connection.
+func (k *kafkaPublisher) Ping(ctx context.Context) error {
+	var err error
+	for _, broker := range k.brokers {
+		var conn *kafka.Conn
+		conn, err = kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn.Close()
+		}
+	}
+	return err
+}
+
+// Close implements port.PublisherInterface.
+func (k *kafkaPublisher) Close() error {
+	return k.writer.Close()
+}
+
+func NewKafkaPublisher(cfg *config.Config, log *slog.Logger) (port.PublisherInterface, error) {
+	if len(cfg.Kafka.Brokers) == 0 {
+		return nil, errors.New("kafka brokers are not configured")
+	}
+
+	return &kafkaPublisher{
+		log: log,
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.Kafka.Brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+		brokers:     cfg.Kafka.Brokers,
+		topicPrefix: cfg.Kafka.TopicPrefix,
+	}, nil
+}
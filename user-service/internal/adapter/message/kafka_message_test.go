@@ -0,0 +1,72 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"user-service/config"
+	"user-service/internal/core/domain/entity"
+)
+
+func TestKafkaMessageEncoding(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Kafka.Brokers = []string{"localhost:9092"}
+	cfg.Kafka.TopicPrefix = "sayur."
+	publisher, err := NewKafkaPublisher(cfg, testLogger())
+	if err != nil {
+		t.Fatalf("NewKafkaPublisher: %v", err)
+	}
+	k := publisher.(*kafkaPublisher)
+
+	notification := entity.NotificationEntity{
+		Email:            "siti@example.com",
+		Message:          "Please verify your account: https://sayur.id/verify?token=abc",
+		NotificationType: "user_verification",
+	}
+	message, err := k.message(context.Background(), k.topicPrefix+notification.NotificationType, notification)
+	if err != nil {
+		t.Fatalf("message: %v", err)
+	}
+
+	if message.Topic != "sayur.user_verification" {
+		t.Errorf("topic = %q, want %q", message.Topic, "sayur.user_verification")
+	}
+	if string(message.Key) != notification.Email {
+		t.Errorf("key = %q, want the email %q", message.Key, notification.Email)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(message.Value, &body); err != nil {
+		t.Fatalf("value is not JSON: %v", err)
+	}
+	want := map[string]string{"email": notification.Email, "message": notification.Message}
+	if len(body) != len(want) || body["email"] != want["email"] || body["message"] != want["message"] {
+		t.Errorf("value = %v, want %v", body, want)
+	}
+
+	if got := (kafkaHeaderCarrier{headers: &message.Headers}).Get("content-type"); got != "application/json" {
+		t.Errorf("content-type header = %q, want application/json", got)
+	}
+}
+
+func TestKafkaMessageCarriesTraceContext(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Kafka.Brokers = []string{"localhost:9092"}
+	publisher, err := NewKafkaPublisher(cfg, testLogger())
+	if err != nil {
+		t.Fatalf("NewKafkaPublisher: %v", err)
+	}
+
+	ctx, want := traced(t)
+	message, err := publisher.(*kafkaPublisher).message(ctx, "user_verification", entity.NotificationEntity{
+		Email:            "siti@example.com",
+		NotificationType: "user_verification",
+	})
+	if err != nil {
+		t.Fatalf("message: %v", err)
+	}
+
+	if got := (kafkaHeaderCarrier{headers: &message.Headers}).Get("traceparent"); got != want {
+		t.Errorf("traceparent header = %q, want %q", got, want)
+	}
+}
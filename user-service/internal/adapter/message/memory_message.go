@@ -0,0 +1,59 @@
+package message
+
+import (
+	"context"
+	"sync"
+	"user-service/internal/core/domain/entity"
+)
+
+// MemoryPublisher keeps published notifications in memory. It is meant for
+// unit tests and local development without a broker.
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	messages []entity.NotificationEntity
+}
+
+// Publish implements port.PublisherInterface.
+func (m *MemoryPublisher) Publish(ctx context.Context, notification entity.NotificationEntity) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, notification)
+	return nil
+}
+
+// Ping implements port.PublisherInterface.
+func (m *MemoryPublisher) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close implements port.PublisherInterface.
+func (m *MemoryPublisher) Close() error {
+	return nil
+}
+
+// Messages returns a copy of every notification published so far.
+func (m *MemoryPublisher) Messages() []entity.NotificationEntity {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := make([]entity.NotificationEntity, len(m.messages))
+	copy(messages, m.messages)
+	return messages
+}
+
+// Reset drops all recorded notifications.
+func (m *MemoryPublisher) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = nil
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
@@ -0,0 +1,41 @@
+package message
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// traced returns a context carrying a sampled span, with the W3C trace
+// context propagator installed until the test finishes, and the traceparent
+// header value that span should propagate.
+func traced(t *testing.T) (context.Context, string) {
+	t.Helper()
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tracetest.NewSpanRecorder()))
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(previous)
+		provider.Shutdown(context.Background())
+	})
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "POST /api/v1/signup")
+	t.Cleanup(func() { span.End() })
+	return ctx, traceparent(span.SpanContext())
+}
+
+func traceparent(sc trace.SpanContext) string {
+	return "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-" + sc.TraceFlags().String()
+}
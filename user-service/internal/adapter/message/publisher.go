@@ -0,0 +1,64 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"user-service/config"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/port"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	BrokerRabbitMQ = "rabbitmq"
+	BrokerKafka    = "kafka"
+	BrokerMemory   = "memory"
+)
+
+// NewPublisher returns the publisher adapter selected by MESSAGE_BROKER.
+func NewPublisher(cfg *config.Config, log *slog.Logger) (port.PublisherInterface, error) {
+	switch cfg.Message.Broker {
+	case "", BrokerRabbitMQ:
+		return NewRabbitMQPublisher(cfg, log)
+	case BrokerKafka:
+		return NewKafkaPublisher(cfg, log)
+	case BrokerMemory:
+		return NewMemoryPublisher(), nil
+	default:
+		return nil, fmt.Errorf("unknown message broker %q", cfg.Message.Broker)
+	}
+}
+
+func encodeNotification(notification entity.NotificationEntity) ([]byte, error) {
+	return json.Marshal(map[string]string{
+		"email":   notification.Email,
+		"message": notification.Message,
+	})
+}
+
+// startPublishSpan starts the producer span whose context gets injected into
+// the message headers.
+func startPublishSpan(ctx context.Context, system, destination string) (context.Context, trace.Span) {
+	return otel.Tracer("user-service/message").Start(ctx, destination+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", system),
+			attribute.String("messaging.destination.name", destination),
+			attribute.String("messaging.operation", "publish"),
+		),
+	)
+}
+
+func endPublishSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -1,32 +1,46 @@
 package message
 
 import (
-	"encoding/json"
+	"context"
+	"log/slog"
+	"sync"
 	"user-service/config"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/port"
 
-	"github.com/labstack/gommon/log"
 	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
 )
 
-func PublishMessage(email, message, notif_type string) error {
-	conn, err := config.NewConfig().NewRabbitMQ()
+type rabbitMQPublisher struct {
+	log *slog.Logger
+	cfg *config.Config
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	password string
+}
+
+// Publish implements port.PublisherInterface.
+func (r *rabbitMQPublisher) Publish(ctx context.Context, notification entity.NotificationEntity) (err error) {
+	ctx, span := startPublishSpan(ctx, BrokerRabbitMQ, notification.NotificationType)
+	defer func() { endPublishSpan(span, err) }()
+
+	conn, err := r.connection(ctx)
 	if err != nil {
-		log.Errorf("[PublishMessage-1] Failed connect to RabbitMQ: %v", err)
+		r.log.ErrorContext(ctx, "failed to connect to rabbitmq", "op", "Publish", "error", err)
 		return err
 	}
 
-	defer conn.Close()
-
 	ch, err := conn.Channel()
 	if err != nil {
-		log.Errorf("[PublishMessage-2] Failed to open a channel: %v", err)
+		r.log.ErrorContext(ctx, "failed to open a channel", "op", "Publish", "error", err)
 		return err
 	}
-
 	defer ch.Close()
 
 	queue, err := ch.QueueDeclare(
-		notif_type,
+		notification.NotificationType,
 		true,
 		false,
 		false,
@@ -34,18 +48,13 @@ func PublishMessage(email, message, notif_type string) error {
 		nil,
 	)
 	if err != nil {
-		log.Errorf("[PublishMessage-3] Failed to declare a queue: %v", err)
+		r.log.ErrorContext(ctx, "failed to declare a queue", "op", "Publish", "queue", notification.NotificationType, "error", err)
 		return err
 	}
 
-	notification := map[string]string{
-		"email":   email,
-		"message": message,
-	}
-
-	body, err := json.Marshal(notification)
+	publishing, err := r.publishing(ctx, notification)
 	if err != nil {
-		log.Errorf("[PublishMessage-4] Failed to marshal notification: %v", err)
+		r.log.ErrorContext(ctx, "failed to marshal notification", "op", "Publish", "error", err)
 		return err
 	}
 
@@ -54,9 +63,113 @@ func PublishMessage(email, message, notif_type string) error {
 		queue.Name,
 		false,
 		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		},
+		publishing,
 	)
 }
+
+// publishing builds the message of notification, with the trace context of
+// ctx in its headers.
+func (r *rabbitMQPublisher) publishing(ctx context.Context, notification entity.NotificationEntity) (amqp.Publishing, error) {
+	body, err := encodeNotification(notification)
+	if err != nil {
+		return amqp.Publishing{}, err
+	}
+
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, amqpHeaderCarrier(headers))
+
+	return amqp.Publishing{
+		ContentType: "application/json",
+		Headers:     headers,
+		Body:        body,
+	}, nil
+}
+
+// Ping implements port.PublisherInterface.
+func (r *rabbitMQPublisher) Ping(ctx context.Context) error {
+	_, err := r.connection(ctx)
+	return err
+}
+
+// Close implements port.PublisherInterface.
+func (r *rabbitMQPublisher) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil || r.conn.IsClosed() {
+		return nil
+	}
+	return r.conn.Close()
+}
+
+// connection returns the shared connection, redialing when the broker has
+// closed it. The dial runs without r.mu held and gives up when ctx is done, so
+// a slow broker does not block other callers past their own deadlines.
+func (r *rabbitMQPublisher) connection(ctx context.Context) (*amqp.Connection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	conn, password := r.conn, r.password
+	r.mu.Unlock()
+	if conn != nil && !conn.IsClosed() {
+		return conn, nil
+	}
+
+	conn, err := r.dial(ctx, password)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn != nil && !r.conn.IsClosed() {
+		// another caller redialed first
+		conn.Close()
+		return r.conn, nil
+	}
+	r.conn = conn
+	return conn, nil
+}
+
+// RotatePassword connects with password and, when the broker accepts it,
+// publishes over the new connection from then on. The old connection is
+// closed; the old password is kept when the new one is refused.
+func (r *rabbitMQPublisher) RotatePassword(ctx context.Context, password string) error {
+	conn, err := r.dial(ctx, password)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	old := r.conn
+	r.conn, r.password = conn, password
+	r.mu.Unlock()
+
+	if old != nil && !old.IsClosed() {
+		old.Close()
+	}
+	return nil
+}
+
+// dial connects with the configured user and password.
+func (r *rabbitMQPublisher) dial(ctx context.Context, password string) (*amqp.Connection, error) {
+	cfg := *r.cfg
+	cfg.RabbitMQ.Password = password
+	return cfg.NewRabbitMQ(ctx)
+}
+
+func NewRabbitMQPublisher(cfg *config.Config, log *slog.Logger) (port.PublisherInterface, error) {
+	conn, err := cfg.NewRabbitMQ(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return &rabbitMQPublisher{
+		log:      log,
+		cfg:      cfg,
+		conn:     conn,
+		password: cfg.RabbitMQ.Password,
+	}, nil
+}
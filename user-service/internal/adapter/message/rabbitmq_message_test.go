@@ -0,0 +1,103 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+	"user-service/config"
+	"user-service/internal/core/domain/entity"
+)
+
+// stalledBroker accepts connections and never answers the AMQP handshake.
+func stalledBroker(t *testing.T) *config.Config {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	var conns []net.Conn
+	var mu sync.Mutex
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+	t.Cleanup(func() {
+		ln.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+
+	addr := ln.Addr().(*net.TCPAddr)
+	cfg := &config.Config{}
+	cfg.RabbitMQ.Host = addr.IP.String()
+	cfg.RabbitMQ.Port = addr.Port
+	cfg.RabbitMQ.User = "guest"
+	cfg.RabbitMQ.Password = "guest"
+	return cfg
+}
+
+func TestRabbitMQPingHonoursContext(t *testing.T) {
+	publisher := &rabbitMQPublisher{log: testLogger(), cfg: stalledBroker(t)}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := publisher.Ping(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Ping with a cancelled context = %v, want %v", err, context.Canceled)
+	}
+
+	// concurrent pings each give up at their own deadline rather than queueing
+	// behind one another's dial
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			if err := publisher.Ping(ctx); err == nil {
+				t.Error("Ping against a stalled broker succeeded")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("pings took %s, want them cut off near their 200ms deadline", elapsed)
+	}
+}
+
+func TestRabbitMQPublishingCarriesTraceContext(t *testing.T) {
+	publisher := &rabbitMQPublisher{log: testLogger(), cfg: &config.Config{}}
+
+	ctx, want := traced(t)
+	publishing, err := publisher.publishing(ctx, entity.NotificationEntity{
+		Email:            "siti@example.com",
+		Message:          "Please verify your account",
+		NotificationType: "user_verification",
+	})
+	if err != nil {
+		t.Fatalf("publishing: %v", err)
+	}
+
+	if publishing.ContentType != "application/json" {
+		t.Errorf("content type = %q, want application/json", publishing.ContentType)
+	}
+	if got := amqpHeaderCarrier(publishing.Headers).Get("traceparent"); got != want {
+		t.Errorf("traceparent header = %q, want %q", got, want)
+	}
+}
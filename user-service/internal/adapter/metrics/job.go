@@ -0,0 +1,9 @@
+package metrics
+
+import "time"
+
+// ObserveJob records one run of a background job. result is success, error
+// or skipped, the latter when another replica holds the job.
+func ObserveJob(job, result string, elapsed time.Duration) {
+	jobRunDuration.WithLabelValues(job, result).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"context"
+	"time"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/port"
+)
+
+type instrumentedPublisher struct {
+	port.PublisherInterface
+	broker string
+}
+
+// Publish implements port.PublisherInterface.
+func (p *instrumentedPublisher) Publish(ctx context.Context, notification entity.NotificationEntity) error {
+	start := time.Now()
+	err := p.PublisherInterface.Publish(ctx, notification)
+
+	publishDuration.WithLabelValues(p.broker, notification.NotificationType).Observe(time.Since(start).Seconds())
+	if err != nil {
+		publishFailures.WithLabelValues(p.broker, notification.NotificationType).Inc()
+	}
+	return err
+}
+
+// InstrumentPublisher wraps a publisher to record publish latency and
+// failures.
+func InstrumentPublisher(publisher port.PublisherInterface, broker string) port.PublisherInterface {
+	return &instrumentedPublisher{
+		PublisherInterface: publisher,
+		broker:             broker,
+	}
+}
@@ -1,13 +1,15 @@
 package adapter
 
 import (
-	"net/http"
+	"errors"
+	"log/slog"
 	"strings"
 	"user-service/config"
-	"user-service/internal/adapter/handler/response"
+	"user-service/internal/core/domain/errs"
+	"user-service/internal/core/port"
+	"user-service/utils/logger"
 
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/gommon/log"
 )
 
 type MiddlewareAdapterInterface interface {
@@ -15,47 +17,43 @@ type MiddlewareAdapterInterface interface {
 }
 
 type middlewareAdapter struct {
-	cfg *config.Config
+	log          *slog.Logger
+	cfg          *config.Config
+	sessionStore port.SessionStoreInterface
 }
 
 func (m *middlewareAdapter) CheckToken() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			respErr := response.DefaultResponse{}
-			redisConn := config.NewRedisClient()
+			ctx := c.Request().Context()
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" {
-				log.Errorf("[MiddlewareAdapter-1] CheckToken: %s", "Missing or Invalid Token")
-				respErr.Message = "Missing or Invalid Token"
-				respErr.Data = nil
-				return c.JSON(http.StatusUnauthorized, respErr)
+				m.log.InfoContext(ctx, "missing or invalid token", "op", "CheckToken")
+				return errs.ErrTokenMissing
 			}
 
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			// fmt.Println("tokenString====" + tokenString + "\n")
-			getSession, err := redisConn.Get(c.Request().Context(), tokenString).Result()
+			session, err := m.sessionStore.Get(ctx, tokenString)
 			if err != nil {
-				log.Errorf("[MiddlewareAdapter-2] CheckToken: %v", err)
-				respErr.Message = "Invalid Token"
-				respErr.Data = nil
-				return c.JSON(http.StatusUnauthorized, respErr)
+				if errors.Is(err, port.ErrSessionNotFound) {
+					m.log.InfoContext(ctx, "session not found", "op", "CheckToken")
+					return err
+				}
+				m.log.ErrorContext(ctx, "failed to get session", "op", "CheckToken", "error", err)
+				return err
 			}
 
-			if len(getSession) == 0 {
-				log.Errorf("[MiddlewareAdapter-3] Session: %v", getSession)
-				respErr.Message = "Session Not Found"
-				respErr.Data = nil
-				return c.JSON(http.StatusUnauthorized, respErr)
-			}
-
-			c.Set("user", getSession)
+			logger.SetUserID(ctx, session.UserID)
+			c.Set("user", session)
 			return next(c)
 		}
 	}
 }
 
-func NewMiddlewareAdapter(cfg *config.Config) MiddlewareAdapterInterface {
+func NewMiddlewareAdapter(cfg *config.Config, log *slog.Logger, sessionStore port.SessionStoreInterface) MiddlewareAdapterInterface {
 	return &middlewareAdapter{
-		cfg: cfg,
+		log:          log,
+		cfg:          cfg,
+		sessionStore: sessionStore,
 	}
 }
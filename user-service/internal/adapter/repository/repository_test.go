@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/model"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowConnPool stands in for a database whose every statement takes longer
+// than any test waits, returning only once the statement context is done.
+type slowConnPool struct{}
+
+var errStatementFinished = errors.New("statement ran to completion")
+
+func (slowConnPool) wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(10 * time.Second):
+		return errStatementFinished
+	}
+}
+
+func (p slowConnPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, p.wait(ctx)
+}
+
+func (p slowConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, p.wait(ctx)
+}
+
+func (p slowConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, p.wait(ctx)
+}
+
+func (p slowConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	p.wait(ctx)
+	return nil
+}
+
+func slowDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: slowConnPool{}}), &gorm.Config{Logger: gormlogger.Discard, TranslateError: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return db
+}
+
+func TestRepositoryStopsWhenContextIsDone(t *testing.T) {
+	repo := NewUserRepository(slowDB(t), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := repo.GetUserByID(ctx, 1)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want %v", err, context.Canceled)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("returned after %s, want right after the cancel", elapsed)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := repo.UpdatePasswordByID(ctx, entity.UserEntity{ID: 1, Password: "hash"})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
+
+func TestUserEntityWithoutRoles(t *testing.T) {
+	user := userEntity(model.User{ID: 7, Email: "budi@example.com"})
+	if user.ID != 7 || user.RoleName != "" {
+		t.Errorf("user = %+v, want id 7 and no role", user)
+	}
+}
+
+// dryRunSQL returns a repository whose statements are built but not run,
+// and the statements it built.
+func dryRunSQL(t *testing.T) (*userRepository, *[]string) {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: slowConnPool{}}), &gorm.Config{Logger: gormlogger.Discard, DryRun: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	statements := []string{}
+	record := func(db *gorm.DB) { statements = append(statements, db.Statement.SQL.String()) }
+	db.Callback().Query().After("gorm:query").Register("test:record", record)
+	db.Callback().Delete().After("gorm:delete").Register("test:record", record)
+
+	return NewUserRepository(db, slog.New(slog.NewTextHandler(io.Discard, nil))), &statements
+}
+
+func TestUnverifiedUserQueriesSkipProtectedUsers(t *testing.T) {
+	repo, statements := dryRunSQL(t)
+
+	if _, err := repo.DeleteUnverifiedUsers(context.Background(), time.Now()); err != nil {
+		t.Fatalf("DeleteUnverifiedUsers: %v", err)
+	}
+	if _, err := repo.GetUnverifiedUsersToRemind(context.Background(), time.Now(), time.Now(), 10); err != nil {
+		t.Fatalf("GetUnverifiedUsersToRemind: %v", err)
+	}
+
+	if len(*statements) < 2 {
+		t.Fatalf("statements = %q, want the delete and the reminder query", *statements)
+	}
+	for _, want := range []string{"suspended_at IS NULL", "created_by_operator = $2"} {
+		if !strings.Contains((*statements)[0], want) {
+			t.Errorf("delete = %q, want it to contain %q", (*statements)[0], want)
+		}
+	}
+	if !strings.Contains((*statements)[1], "verification_remind_attempted_at < $") {
+		t.Errorf("reminder query = %q, want it to skip recent failed attempts", (*statements)[1])
+	}
+}
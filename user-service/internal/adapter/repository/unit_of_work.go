@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"user-service/internal/core/port"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type unitOfWork struct {
+	db  *gorm.DB
+	log *slog.Logger
+}
+
+// Do implements port.UnitOfWorkInterface.
+func (w *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := Conn(ctx, w.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+	if err != nil {
+		w.log.DebugContext(ctx, "transaction rolled back", "op", "UnitOfWork.Do", "error", err)
+	}
+	return err
+}
+
+// Conn returns the transaction carried by ctx, or db bound to ctx when there
+// is none. Repositories and the Postgres session store use it for every
+// query so they join a unit of work and stop when the request is cancelled.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
+
+func NewUnitOfWork(db *gorm.DB, log *slog.Logger) port.UnitOfWorkInterface {
+	return &unitOfWork{
+		db:  db,
+		log: log,
+	}
+}
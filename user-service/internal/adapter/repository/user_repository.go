@@ -3,148 +3,362 @@ package repository
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"time"
 	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/errs"
 	"user-service/internal/core/domain/model"
 
-	"github.com/labstack/gommon/log"
 	"gorm.io/gorm"
 )
 
 type UserRepositoryInterface interface {
 	GetUserByEmail(ctx context.Context, email string) (*entity.UserEntity, error)
-	CreateUserAccount(ctx context.Context, req entity.UserEntity) error
+	CreateUserAccount(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, error)
 	UpdateUserVerified(ctx context.Context, userID int64) (*entity.UserEntity, error)
 	UpdatePasswordByID(ctx context.Context, req entity.UserEntity) error
+	// RehashPassword replaces the password hash of a user with newHash only
+	// while it still is oldHash, so a password changed meanwhile is kept.
+	RehashPassword(ctx context.Context, userID int64, oldHash, newHash string) error
+	GetUserByID(ctx context.Context, userID int64) (*entity.UserEntity, error)
+	GetUsersByIDs(ctx context.Context, userIDs []int64) ([]entity.UserEntity, error)
+	FindUserByEmail(ctx context.Context, email string) (*entity.UserEntity, error)
+	CreateUser(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, error)
+	UpdateSuspended(ctx context.Context, userID int64, suspended bool) error
+	UpdateRole(ctx context.Context, userID int64, roleName string) error
+	// GetUnverifiedUsersToRemind returns up to limit unverified users created
+	// before createdBefore that were not reminded yet, leaving out those whose
+	// last failed reminder was attempted after attemptedBefore.
+	GetUnverifiedUsersToRemind(ctx context.Context, createdBefore, attemptedBefore time.Time, limit int) ([]entity.UserEntity, error)
+	MarkVerificationReminded(ctx context.Context, userID int64) error
+	// MarkVerificationRemindAttempted records a reminder that could not be
+	// sent.
+	MarkVerificationRemindAttempted(ctx context.Context, userID int64) error
+	// DeleteUnverifiedUsers deletes unverified users created before
+	// createdBefore and returns how many there were. Suspended users and
+	// users created by an operator are kept.
+	DeleteUnverifiedUsers(ctx context.Context, createdBefore time.Time) (int64, error)
 }
 
 type userRepository struct {
-	db *gorm.DB
+	db  *gorm.DB
+	log *slog.Logger
 }
 
 // UpdatePasswordByID implement UserRepositoryInterface
 func (u *userRepository) UpdatePasswordByID(ctx context.Context, req entity.UserEntity) error {
 	modelUser := model.User{}
 
-	if err := u.db.Where("id = ?", req.ID).First(&modelUser).Error; err != nil {
+	if err := Conn(ctx, u.db).Where("id = ?", req.ID).First(&modelUser).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			err = errors.New("404")
-			log.Errorf("[UserRepository-9] UpdatePasswordByID: %v", err)
-			return err
+			u.log.InfoContext(ctx, "user not found", "op", "UpdatePasswordByID", "user_id", req.ID)
+			return errs.ErrUserNotFound
 		}
-		log.Errorf("[UserRepository-10] UpdatePasswordByID: %v", err)
+		u.log.ErrorContext(ctx, "failed to find user", "op", "UpdatePasswordByID", "user_id", req.ID, "error", err)
 		return err
 	}
 	modelUser.Password = req.Password
-	if err := u.db.Save(&modelUser).Error; err != nil {
-		log.Errorf("[UserRepository-11] UpdatePasswordByID: %v", err)
+	if err := Conn(ctx, u.db).Save(&modelUser).Error; err != nil {
+		u.log.ErrorContext(ctx, "failed to update password", "op", "UpdatePasswordByID", "user_id", req.ID, "error", err)
 		return err
 	}
 
 	return nil
 }
 
+// RehashPassword implement UserRepositoryInterface
+func (u *userRepository) RehashPassword(ctx context.Context, userID int64, oldHash, newHash string) error {
+	result := Conn(ctx, u.db).Model(&model.User{}).Where("id = ? AND password = ?", userID, oldHash).Update("password", newHash)
+	if result.Error != nil {
+		u.log.ErrorContext(ctx, "failed to rehash password", "op", "RehashPassword", "user_id", userID, "error", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		u.log.InfoContext(ctx, "password changed before rehash, kept", "op", "RehashPassword", "user_id", userID)
+	}
+	return nil
+}
+
 func (u *userRepository) UpdateUserVerified(ctx context.Context, userID int64) (*entity.UserEntity, error) {
 	modelUser := model.User{}
 
-	if err := u.db.Where("id = ?", userID).Preload("Roles").First(&modelUser).Error; err != nil {
+	if err := Conn(ctx, u.db).Where("id = ?", userID).Preload("Roles").First(&modelUser).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			err = errors.New("404")
-			log.Errorf("[UserRepository-6] UpdateUserVerified: %v", err)
-			return nil, err
+			u.log.InfoContext(ctx, "user not found", "op", "UpdateUserVerified", "user_id", userID)
+			return nil, errs.ErrUserNotFound
 		}
-		log.Errorf("[UserRepository-7] UpdateUserVerified: %v", err)
+		u.log.ErrorContext(ctx, "failed to find user", "op", "UpdateUserVerified", "user_id", userID, "error", err)
 		return nil, err
 	}
 
 	modelUser.IsVerified = true
-	if err := u.db.Save(&modelUser).Error; err != nil {
-		log.Errorf("[UserRepository-8] UpdateUserVerified: %v", err)
+	if err := Conn(ctx, u.db).Save(&modelUser).Error; err != nil {
+		u.log.ErrorContext(ctx, "failed to mark user verified", "op", "UpdateUserVerified", "user_id", userID, "error", err)
 		return nil, err
 	}
 
-	return &entity.UserEntity{
-		ID:         userID,
-		Name:       modelUser.Name,
-		Email:      modelUser.Email,
-		RoleName:   modelUser.Roles[0].Name,
-		Address:    modelUser.Address,
-		Lat:        modelUser.Lat,
-		Lng:        modelUser.Lng,
-		Phone:      modelUser.Phone,
-		Photo:      modelUser.Photo,
-		IsVerified: modelUser.IsVerified,
-	}, nil
+	user := userEntity(modelUser)
+	return &user, nil
 }
 
-// GetUserByEmail implement UserRepositoryInterface
+// GetUserByEmail implement UserRepositoryInterface. It returns the user
+// with the password hash, verified or not; callers check IsVerified.
 func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*entity.UserEntity, error) {
 	modelUser := model.User{}
 
 	// Preload Roles itu bisa cek di model.User.Roles
-	if err := u.db.Where("email = ? AND is_verified = ?", email, true).
+	if err := Conn(ctx, u.db).Where("lower(email) = lower(?)", email).
 		Preload("Roles").First(&modelUser).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			err = errors.New("404")
-			log.Infof("[UserRepository-1] GetUserByEmail: User not found")
-			return nil, err
+			u.log.InfoContext(ctx, "user not found", "op", "GetUserByEmail", "email", email)
+			return nil, errs.ErrUserNotFound
 		}
-		log.Errorf("[UserRepository-2] GetUserByEmail: %v", err)
+		u.log.ErrorContext(ctx, "failed to find user", "op", "GetUserByEmail", "email", email, "error", err)
 		return nil, err
 	}
 
-	return &entity.UserEntity{
-		ID:         modelUser.ID,
-		Name:       modelUser.Name,
-		Email:      modelUser.Email,
-		Password:   modelUser.Password,
-		RoleName:   modelUser.Roles[0].Name,
-		Address:    modelUser.Address,
-		Lat:        modelUser.Lat,
-		Lng:        modelUser.Lng,
-		Phone:      modelUser.Phone,
-		Photo:      modelUser.Photo,
-		IsVerified: modelUser.IsVerified,
-	}, nil
+	user := userEntity(modelUser)
+	user.Password = modelUser.Password
+	return &user, nil
 }
 
-func (u *userRepository) CreateUserAccount(ctx context.Context, req entity.UserEntity) error {
+// CreateUserAccount implement UserRepositoryInterface. It creates an
+// unverified customer; the verification token is the caller's to create.
+func (u *userRepository) CreateUserAccount(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, error) {
 	modelRole := model.Role{}
-	err := u.db.Where("name = ?", "Customer").First(&modelRole).Error
+	err := Conn(ctx, u.db).Where("name = ?", entity.RoleCustomer).First(&modelRole).Error
 	if err != nil {
-		log.Errorf("[UserRepository-5] CreateUserAccount: %v", err)
-		return err
+		u.log.ErrorContext(ctx, "failed to find role Customer", "op", "CreateUserAccount", "error", err)
+		return nil, err
 	}
 
 	modelUser := model.User{
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: req.Password,
+		Language: req.Language,
 		Roles:    []model.Role{modelRole},
 	}
 
-	if err := u.db.Create(&modelUser).Error; err != nil {
-		log.Errorf("[UserRepository-3] CreateUserAccount: %v", err)
+	if err := Conn(ctx, u.db).Create(&modelUser).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			u.log.InfoContext(ctx, "email already registered", "op", "CreateUserAccount", "email", req.Email)
+			return nil, errs.ErrEmailTaken.WithCause(err)
+		}
+		u.log.ErrorContext(ctx, "failed to create user", "op", "CreateUserAccount", "email", req.Email, "error", err)
+		return nil, err
+	}
+
+	user := userEntity(modelUser)
+	return &user, nil
+}
+
+// GetUserByID implement UserRepositoryInterface
+func (u *userRepository) GetUserByID(ctx context.Context, userID int64) (*entity.UserEntity, error) {
+	modelUser := model.User{}
+
+	if err := Conn(ctx, u.db).Where("id = ?", userID).Preload("Roles").First(&modelUser).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			u.log.InfoContext(ctx, "user not found", "op", "GetUserByID", "user_id", userID)
+			return nil, errs.ErrUserNotFound
+		}
+		u.log.ErrorContext(ctx, "failed to find user", "op", "GetUserByID", "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	user := userEntity(modelUser)
+	return &user, nil
+}
+
+// GetUsersByIDs implement UserRepositoryInterface
+func (u *userRepository) GetUsersByIDs(ctx context.Context, userIDs []int64) ([]entity.UserEntity, error) {
+	modelUsers := []model.User{}
+
+	if err := Conn(ctx, u.db).Where("id IN ?", userIDs).Preload("Roles").Find(&modelUsers).Error; err != nil {
+		u.log.ErrorContext(ctx, "failed to find users", "op", "GetUsersByIDs", "count", len(userIDs), "error", err)
+		return nil, err
+	}
+
+	users := make([]entity.UserEntity, 0, len(modelUsers))
+	for _, modelUser := range modelUsers {
+		users = append(users, userEntity(modelUser))
+	}
+	return users, nil
+}
+
+// FindUserByEmail implement UserRepositoryInterface. Unlike GetUserByEmail
+// it leaves out the password hash.
+func (u *userRepository) FindUserByEmail(ctx context.Context, email string) (*entity.UserEntity, error) {
+	modelUser := model.User{}
+
+	if err := Conn(ctx, u.db).Where("lower(email) = lower(?)", email).Preload("Roles").First(&modelUser).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			u.log.InfoContext(ctx, "user not found", "op", "FindUserByEmail", "email", email)
+			return nil, errs.ErrUserNotFound
+		}
+		u.log.ErrorContext(ctx, "failed to find user", "op", "FindUserByEmail", "email", email, "error", err)
+		return nil, err
+	}
+
+	user := userEntity(modelUser)
+	return &user, nil
+}
+
+// CreateUser implement UserRepositoryInterface. It creates the user with
+// req.RoleName and verification state as given, without a verification
+// token.
+func (u *userRepository) CreateUser(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, error) {
+	modelRole := model.Role{}
+	if err := Conn(ctx, u.db).Where("name = ?", req.RoleName).First(&modelRole).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.ErrRoleNotFound
+		}
+		u.log.ErrorContext(ctx, "failed to find role", "op", "CreateUser", "role", req.RoleName, "error", err)
+		return nil, err
+	}
+
+	modelUser := model.User{
+		Name:       req.Name,
+		Email:      req.Email,
+		Password:   req.Password,
+		Phone:      req.Phone,
+		Address:    req.Address,
+		IsVerified: req.IsVerified,
+		Language:   req.Language,
+		Roles:      []model.Role{modelRole},
+
+		CreatedByOperator: true,
+	}
+
+	if err := Conn(ctx, u.db).Create(&modelUser).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			u.log.InfoContext(ctx, "email already registered", "op", "CreateUser", "email", req.Email)
+			return nil, errs.ErrEmailTaken.WithCause(err)
+		}
+		u.log.ErrorContext(ctx, "failed to create user", "op", "CreateUser", "email", req.Email, "error", err)
+		return nil, err
+	}
+
+	user := userEntity(modelUser)
+	return &user, nil
+}
+
+// UpdateSuspended implement UserRepositoryInterface
+func (u *userRepository) UpdateSuspended(ctx context.Context, userID int64, suspended bool) error {
+	var suspendedAt *time.Time
+	if suspended {
+		now := time.Now()
+		suspendedAt = &now
+	}
+
+	result := Conn(ctx, u.db).Model(&model.User{}).Where("id = ?", userID).Update("suspended_at", suspendedAt)
+	if result.Error != nil {
+		u.log.ErrorContext(ctx, "failed to update suspension", "op", "UpdateSuspended", "user_id", userID, "error", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errs.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateRole implement UserRepositoryInterface. Users hold a single role, so
+// the new role replaces the current ones.
+func (u *userRepository) UpdateRole(ctx context.Context, userID int64, roleName string) error {
+	modelRole := model.Role{}
+	if err := Conn(ctx, u.db).Where("name = ?", roleName).First(&modelRole).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.ErrRoleNotFound
+		}
+		u.log.ErrorContext(ctx, "failed to find role", "op", "UpdateRole", "role", roleName, "error", err)
+		return err
+	}
+
+	modelUser := model.User{ID: userID}
+	if err := Conn(ctx, u.db).Model(&modelUser).Association("Roles").Replace([]model.Role{modelRole}); err != nil {
+		u.log.ErrorContext(ctx, "failed to replace roles", "op", "UpdateRole", "user_id", userID, "error", err)
 		return err
 	}
+	return nil
+}
 
-	modelVerify := model.VerificationToken{
-		UserID:    modelUser.ID,
-		Token:     req.Token,
-		TokenType: "email_verification",
-		ExpiresAt: time.Now().Add(1 * time.Hour),
+// GetUnverifiedUsersToRemind implement UserRepositoryInterface
+func (u *userRepository) GetUnverifiedUsersToRemind(ctx context.Context, createdBefore, attemptedBefore time.Time, limit int) ([]entity.UserEntity, error) {
+	modelUsers := []model.User{}
+
+	err := Conn(ctx, u.db).
+		Where("is_verified = ? AND verification_reminded_at IS NULL AND suspended_at IS NULL AND created_at < ?", false, createdBefore).
+		Where("verification_remind_attempted_at IS NULL OR verification_remind_attempted_at < ?", attemptedBefore).
+		Order("id").Limit(limit).Preload("Roles").Find(&modelUsers).Error
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to find unverified users", "op", "GetUnverifiedUsersToRemind", "error", err)
+		return nil, err
 	}
 
-	if err := u.db.Create(&modelVerify).Error; err != nil {
-		log.Errorf("[UserRepository-4] CreateUserAccount: %v", err)
+	users := make([]entity.UserEntity, 0, len(modelUsers))
+	for _, modelUser := range modelUsers {
+		users = append(users, userEntity(modelUser))
+	}
+	return users, nil
+}
+
+// MarkVerificationReminded implement UserRepositoryInterface
+func (u *userRepository) MarkVerificationReminded(ctx context.Context, userID int64) error {
+	err := Conn(ctx, u.db).Model(&model.User{}).Where("id = ?", userID).Update("verification_reminded_at", time.Now()).Error
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to mark user reminded", "op", "MarkVerificationReminded", "user_id", userID, "error", err)
 		return err
 	}
+	return nil
+}
 
+// MarkVerificationRemindAttempted implement UserRepositoryInterface
+func (u *userRepository) MarkVerificationRemindAttempted(ctx context.Context, userID int64) error {
+	err := Conn(ctx, u.db).Model(&model.User{}).Where("id = ?", userID).Update("verification_remind_attempted_at", time.Now()).Error
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to record reminder attempt", "op", "MarkVerificationRemindAttempted", "user_id", userID, "error", err)
+		return err
+	}
 	return nil
 }
 
-func NewUserRepository(db *gorm.DB) *userRepository {
+// DeleteUnverifiedUsers implement UserRepositoryInterface. Roles, tokens
+// and sessions of the users go with them through ON DELETE CASCADE.
+func (u *userRepository) DeleteUnverifiedUsers(ctx context.Context, createdBefore time.Time) (int64, error) {
+	result := Conn(ctx, u.db).
+		Where("is_verified = ? AND suspended_at IS NULL AND created_by_operator = ? AND created_at < ?", false, false, createdBefore).
+		Delete(&model.User{})
+	if result.Error != nil {
+		u.log.ErrorContext(ctx, "failed to delete unverified users", "op", "DeleteUnverifiedUsers", "error", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// userEntity converts a user loaded with its roles, leaving out the password.
+func userEntity(modelUser model.User) entity.UserEntity {
+	user := entity.UserEntity{
+		ID:          modelUser.ID,
+		Name:        modelUser.Name,
+		Email:       modelUser.Email,
+		Address:     modelUser.Address,
+		Lat:         modelUser.Lat,
+		Lng:         modelUser.Lng,
+		Phone:       modelUser.Phone,
+		Photo:       modelUser.Photo,
+		IsVerified:  modelUser.IsVerified,
+		Language:    modelUser.Language,
+		IsSuspended: modelUser.SuspendedAt != nil,
+	}
+	if len(modelUser.Roles) > 0 {
+		user.RoleName = modelUser.Roles[0].Name
+	}
+	return user
+}
+
+func NewUserRepository(db *gorm.DB, log *slog.Logger) *userRepository {
 	return &userRepository{
-		db: db,
+		db:  db,
+		log: log,
 	}
 }
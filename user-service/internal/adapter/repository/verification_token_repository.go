@@ -3,69 +3,159 @@ package repository
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"time"
 	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/errs"
 	"user-service/internal/core/domain/model"
+	"user-service/utils/conv"
 
-	"github.com/labstack/gommon/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// VerificationTokenRepositoryInterface stores emailed tokens by their hash;
+// callers pass and receive the raw token.
 type VerificationTokenRepositoryInterface interface {
+	// CreateVerificationToken stores a token and invalidates the user's
+	// older tokens of the same type.
 	CreateVerificationToken(ctx context.Context, req entity.VerificationTokenEntity) error
-	GetDataByToken(ctx context.Context, token string) (*entity.VerificationTokenEntity, error)
+	// ConsumeToken marks an unused, unexpired token of tokenType as used and
+	// returns it. A token can be consumed only once.
+	ConsumeToken(ctx context.Context, token, tokenType string) (*entity.VerificationTokenEntity, error)
+	// GetToken returns an unused, unexpired token of tokenType without
+	// consuming it, failing the way ConsumeToken would.
+	GetToken(ctx context.Context, token, tokenType string) (*entity.VerificationTokenEntity, error)
+	// DeleteStaleTokens deletes tokens that expired or were consumed before
+	// before and returns how many there were.
+	DeleteStaleTokens(ctx context.Context, before time.Time) (int64, error)
 }
 
 type verificationTokenRepository struct {
-	db *gorm.DB
+	db  *gorm.DB
+	log *slog.Logger
 }
 
-// GetDataByToken implements VerificationTokenRepositoryInterface.
-func (v *verificationTokenRepository) GetDataByToken(ctx context.Context, token string) (*entity.VerificationTokenEntity, error) {
+// ConsumeToken implements VerificationTokenRepositoryInterface. Checking and
+// consuming is a single UPDATE, so concurrent requests cannot both use the
+// same token.
+func (v *verificationTokenRepository) ConsumeToken(ctx context.Context, token, tokenType string) (*entity.VerificationTokenEntity, error) {
 	modelToken := model.VerificationToken{}
+	hash := conv.HashToken(token)
+	now := time.Now()
 
-	if err := v.db.Where("token = ?", token).First(&modelToken).Error; err != nil {
+	result := Conn(ctx, v.db).Model(&modelToken).
+		Clauses(clause.Returning{}).
+		Where("token = ? AND token_type = ? AND consumed_at IS NULL AND expires_at > ?", hash, tokenType, now).
+		Updates(map[string]interface{}{"consumed_at": now, "updated_at": now})
+	if result.Error != nil {
+		v.log.ErrorContext(ctx, "failed to consume verification token", "op", "ConsumeToken", "error", result.Error)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, v.rejection(ctx, hash, tokenType)
+	}
+
+	return &entity.VerificationTokenEntity{
+		ID:        modelToken.ID,
+		UserID:    modelToken.UserID,
+		Token:     token,
+		TokenType: modelToken.TokenType,
+		ExpiresAt: modelToken.ExpiresAt,
+	}, nil
+}
+
+// GetToken implements VerificationTokenRepositoryInterface.
+func (v *verificationTokenRepository) GetToken(ctx context.Context, token, tokenType string) (*entity.VerificationTokenEntity, error) {
+	modelToken := model.VerificationToken{}
+	hash := conv.HashToken(token)
+
+	err := Conn(ctx, v.db).
+		Where("token = ? AND token_type = ? AND consumed_at IS NULL AND expires_at > ?", hash, tokenType, time.Now()).
+		First(&modelToken).Error
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			err = errors.New("404") // di define supaya mudah di identifikasi di handler, buat breakdown error
-			log.Errorf("[VerificationTokenRepository-3] GetDataByToken: %v", err)
-			return nil, err
+			return nil, v.rejection(ctx, hash, tokenType)
 		}
-		log.Errorf("[VerificationTokenRepository-2] GetDataByToken: %v", err)
+		v.log.ErrorContext(ctx, "failed to find verification token", "op", "GetToken", "error", err)
 		return nil, err
 	}
 
-	currentTime := time.Now()
-	if currentTime.Before(modelToken.ExpiresAt) {
-		err := errors.New("401")
-		log.Errorf("[VerificationTokenRepository-4] GetDataByToken: %v", err)
-		return nil, err
-	}
 	return &entity.VerificationTokenEntity{
 		ID:        modelToken.ID,
 		UserID:    modelToken.UserID,
-		Token:     modelToken.Token,
+		Token:     token,
 		TokenType: modelToken.TokenType,
 		ExpiresAt: modelToken.ExpiresAt,
 	}, nil
 }
 
+// rejection tells why a token could not be consumed. Only expiry is reported
+// as such; unknown, used and mistyped tokens are all just invalid.
+func (v *verificationTokenRepository) rejection(ctx context.Context, hash, tokenType string) error {
+	modelToken := model.VerificationToken{}
+	if err := Conn(ctx, v.db).Where("token = ?", hash).First(&modelToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			v.log.InfoContext(ctx, "verification token not found", "op", "ConsumeToken")
+			return errs.ErrTokenInvalid
+		}
+		v.log.ErrorContext(ctx, "failed to find verification token", "op", "ConsumeToken", "error", err)
+		return err
+	}
+
+	switch {
+	case modelToken.TokenType != tokenType:
+		v.log.WarnContext(ctx, "verification token of wrong type", "op", "ConsumeToken", "user_id", modelToken.UserID, "token_type", modelToken.TokenType, "want", tokenType)
+		return errs.ErrTokenInvalid
+	case modelToken.ConsumedAt != nil:
+		v.log.InfoContext(ctx, "verification token already used", "op", "ConsumeToken", "user_id", modelToken.UserID)
+		return errs.ErrTokenInvalid
+	default:
+		v.log.InfoContext(ctx, "verification token expired", "op", "ConsumeToken", "user_id", modelToken.UserID)
+		return errs.ErrTokenExpired
+	}
+}
+
 // CreateVerificationToken implements VerificationTokenRepositoryInterface.
 func (v *verificationTokenRepository) CreateVerificationToken(ctx context.Context, req entity.VerificationTokenEntity) error {
+	now := time.Now()
 	modelVerificationToken := model.VerificationToken{
 		UserID:    req.UserID,
-		Token:     req.Token,
+		Token:     conv.HashToken(req.Token),
 		TokenType: req.TokenType,
+		ExpiresAt: req.ExpiresAt,
 	}
 
-	if err := v.db.Create(&modelVerificationToken).Error; err != nil {
-		log.Errorf("[VerificationTokenRepository-1] CreateVerificationToken: %v", err)
+	err := Conn(ctx, v.db).Transaction(func(tx *gorm.DB) error {
+		// only the latest link sent to the user works
+		err := tx.Model(&model.VerificationToken{}).
+			Where("user_id = ? AND token_type = ? AND consumed_at IS NULL", req.UserID, req.TokenType).
+			Updates(map[string]interface{}{"consumed_at": now, "updated_at": now}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&modelVerificationToken).Error
+	})
+	if err != nil {
+		v.log.ErrorContext(ctx, "failed to create verification token", "op", "CreateVerificationToken", "user_id", req.UserID, "error", err)
 		return err
 	}
 	return nil
 }
 
-func NewVerificationTokenRepository(db *gorm.DB) VerificationTokenRepositoryInterface {
+// DeleteStaleTokens implements VerificationTokenRepositoryInterface.
+func (v *verificationTokenRepository) DeleteStaleTokens(ctx context.Context, before time.Time) (int64, error) {
+	result := Conn(ctx, v.db).Where("expires_at < ? OR consumed_at < ?", before, before).Delete(&model.VerificationToken{})
+	if result.Error != nil {
+		v.log.ErrorContext(ctx, "failed to delete stale verification tokens", "op", "DeleteStaleTokens", "error", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func NewVerificationTokenRepository(db *gorm.DB, log *slog.Logger) VerificationTokenRepositoryInterface {
 	return &verificationTokenRepository{
-		db: db,
+		db:  db,
+		log: log,
 	}
 }
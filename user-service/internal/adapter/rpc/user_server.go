@@ -0,0 +1,154 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/errs"
+	"user-service/internal/core/service"
+	userv1 "user-service/proto/user/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxUsersByIDs bounds a GetUsersByIDs call.
+const maxUsersByIDs = 500
+
+// codeByError maps domain error codes to gRPC codes. Anything else is
+// reported as Internal without its message.
+var codeByError = map[errs.Code]codes.Code{
+	errs.CodeBadRequest:         codes.InvalidArgument,
+	errs.CodeValidation:         codes.InvalidArgument,
+	errs.CodeNotFound:           codes.NotFound,
+	errs.CodeUserNotFound:       codes.NotFound,
+	errs.CodeUnauthorized:       codes.Unauthenticated,
+	errs.CodeInvalidCredentials: codes.Unauthenticated,
+	errs.CodeTokenMissing:       codes.Unauthenticated,
+	errs.CodeTokenInvalid:       codes.Unauthenticated,
+	errs.CodeTokenExpired:       codes.Unauthenticated,
+	errs.CodeSessionNotFound:    codes.Unauthenticated,
+	errs.CodeForbidden:          codes.PermissionDenied,
+	errs.CodeAccountSuspended:   codes.PermissionDenied,
+	errs.CodeAccountUnverified:  codes.PermissionDenied,
+	errs.CodeRoleNotFound:       codes.NotFound,
+	errs.CodeConflict:           codes.AlreadyExists,
+	errs.CodeEmailTaken:         codes.AlreadyExists,
+	errs.CodeUnavailable:        codes.Unavailable,
+	errs.CodeTooManyRequests:    codes.ResourceExhausted,
+	errs.CodeWeakPassword:       codes.InvalidArgument,
+}
+
+type userServer struct {
+	userv1.UnimplementedUserServiceServer
+	userService service.UserServiceInterface
+}
+
+// ValidateToken implements userv1.UserServiceServer.
+func (s *userServer) ValidateToken(ctx context.Context, req *userv1.ValidateTokenRequest) (*userv1.ValidateTokenResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	session, err := s.userService.ValidateAccessToken(ctx, req.GetToken())
+	if err != nil {
+		if isUnauthenticated(err) {
+			return &userv1.ValidateTokenResponse{Valid: false}, nil
+		}
+		return nil, statusError(err)
+	}
+
+	return &userv1.ValidateTokenResponse{
+		Valid:     true,
+		UserId:    session.UserID,
+		Name:      session.Name,
+		Email:     session.Email,
+		Role:      session.RoleName,
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+	}, nil
+}
+
+// GetUser implements userv1.UserServiceServer.
+func (s *userServer) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.GetUserResponse, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be positive")
+	}
+
+	user, err := s.userService.GetUserByID(ctx, req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &userv1.GetUserResponse{User: userMessage(*user)}, nil
+}
+
+// GetUsersByIDs implements userv1.UserServiceServer.
+func (s *userServer) GetUsersByIDs(ctx context.Context, req *userv1.GetUsersByIDsRequest) (*userv1.GetUsersByIDsResponse, error) {
+	if len(req.GetIds()) > maxUsersByIDs {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d ids per call, got %d", maxUsersByIDs, len(req.GetIds()))
+	}
+
+	users, err := s.userService.GetUsersByIDs(ctx, req.GetIds())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &userv1.GetUsersByIDsResponse{Users: make([]*userv1.User, 0, len(users))}
+	for _, user := range users {
+		resp.Users = append(resp.Users, userMessage(user))
+	}
+	return resp, nil
+}
+
+// CheckPermission implements userv1.UserServiceServer.
+func (s *userServer) CheckPermission(ctx context.Context, req *userv1.CheckPermissionRequest) (*userv1.CheckPermissionResponse, error) {
+	if req.GetUserId() <= 0 || req.GetPermission() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id and permission are required")
+	}
+
+	allowed, role, err := s.userService.CheckPermission(ctx, req.GetUserId(), req.GetPermission())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &userv1.CheckPermissionResponse{Allowed: allowed, Role: role}, nil
+}
+
+func userMessage(user entity.UserEntity) *userv1.User {
+	return &userv1.User{
+		Id:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Role:       user.RoleName,
+		Address:    user.Address,
+		Lat:        user.Lat,
+		Lng:        user.Lng,
+		Phone:      user.Phone,
+		Photo:      user.Photo,
+		IsVerified: user.IsVerified,
+		Language:   user.Language,
+	}
+}
+
+func isUnauthenticated(err error) bool {
+	var domainErr *errs.Error
+	return errors.As(err, &domainErr) && codeByError[domainErr.Code] == codes.Unauthenticated
+}
+
+// statusError converts a service error into a gRPC status. Only domain error
+// messages reach the caller.
+func statusError(err error) error {
+	var domainErr *errs.Error
+	if !errors.As(err, &domainErr) {
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	code, ok := codeByError[domainErr.Code]
+	if !ok {
+		return status.Error(codes.Internal, "internal server error")
+	}
+	return status.Error(code, domainErr.Message)
+}
+
+func NewUserServer(userService service.UserServiceInterface) userv1.UserServiceServer {
+	return &userServer{userService: userService}
+}
@@ -0,0 +1,168 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"time"
+	"user-service/internal/adapter/metrics"
+	"user-service/utils/logger"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// Job is a unit of background work run on a cron schedule.
+type Job struct {
+	Name     string
+	Schedule string
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs jobs in process on every replica, but a job only runs on
+// the replica that wins its Postgres advisory lock; the others skip that
+// run. The time of the last run of each job is kept in job_runs, so a replica
+// whose clock fires after the winner released the lock skips the run too. A
+// job still running when its next run is due is skipped as well.
+type Scheduler struct {
+	cron   *cron.Cron
+	db     *sql.DB
+	log    *slog.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Add registers job. It fails when the schedule does not parse.
+func (s *Scheduler) Add(job Job) error {
+	schedule, err := cron.ParseStandard(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("schedule job %s: %w", job.Name, err)
+	}
+
+	key := lockKey(job.Name)
+	var id cron.EntryID
+	id = s.cron.Schedule(schedule, cron.FuncJob(func() {
+		// Prev is the time the run was due, the same on every replica for
+		// clock aligned schedules
+		tick := s.cron.Entry(id).Prev
+		if tick.IsZero() {
+			tick = time.Now()
+		}
+		s.run(job, schedule, key, tick)
+	}))
+	s.log.Info("job scheduled", "job", job.Name, "schedule", job.Schedule)
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops scheduling and waits for running jobs until ctx is done, then
+// cancels them.
+func (s *Scheduler) Stop(ctx context.Context) {
+	defer s.cancel()
+
+	select {
+	case <-s.cron.Stop().Done():
+	case <-ctx.Done():
+		s.log.Warn("jobs still running at shutdown, cancelling them")
+	}
+}
+
+func (s *Scheduler) run(job Job, schedule cron.Schedule, key int64, tick time.Time) {
+	ctx := logger.WithRequestID(s.ctx, uuid.New().String())
+	log := s.log.With("job", job.Name)
+	start := time.Now()
+
+	result := "success"
+	defer func() {
+		if r := recover(); r != nil {
+			log.ErrorContext(ctx, "job panicked", "panic", r)
+			result = "error"
+		}
+		metrics.ObserveJob(job.Name, result, time.Since(start))
+	}()
+
+	// the lock belongs to the database session, so it is taken and released
+	// on one dedicated connection
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get connection for job lock", "error", err)
+		result = "error"
+		return
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		log.ErrorContext(ctx, "failed to take job lock", "error", err)
+		result = "error"
+		return
+	}
+	if !locked {
+		log.DebugContext(ctx, "job running on another replica, skipped")
+		result = "skipped"
+		return
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			log.ErrorContext(ctx, "failed to release job lock", "error", err)
+		}
+	}()
+
+	var lastRun time.Time
+	err = conn.QueryRowContext(ctx, "SELECT run_at FROM job_runs WHERE name = $1", job.Name).Scan(&lastRun)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.ErrorContext(ctx, "failed to read last job run", "error", err)
+		result = "error"
+		return
+	}
+	if err == nil && ranThisTick(schedule, lastRun, tick) {
+		log.DebugContext(ctx, "job already ran on another replica, skipped", "last_run", lastRun)
+		result = "skipped"
+		return
+	}
+	if _, err := conn.ExecContext(ctx,
+		"INSERT INTO job_runs (name, run_at) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET run_at = EXCLUDED.run_at",
+		job.Name, tick); err != nil {
+		log.ErrorContext(ctx, "failed to record job run", "error", err)
+		result = "error"
+		return
+	}
+
+	log.InfoContext(ctx, "job started")
+	if err := job.Run(ctx); err != nil {
+		log.ErrorContext(ctx, "job failed", "duration", time.Since(start).String(), "error", err)
+		result = "error"
+		return
+	}
+	log.InfoContext(ctx, "job finished", "duration", time.Since(start).String())
+}
+
+// ranThisTick reports whether the run recorded at lastRun covers tick: no run
+// was due between them.
+func ranThisTick(schedule cron.Schedule, lastRun, tick time.Time) bool {
+	return schedule.Next(lastRun).After(tick)
+}
+
+// lockKey derives the advisory lock of a job from its name.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("sayur-user-service:job:" + name))
+	return int64(h.Sum64())
+}
+
+func New(db *sql.DB, log *slog.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cron:   cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger))),
+		db:     db,
+		log:    log.With("component", "scheduler"),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+func TestRanThisTick(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.DateTime, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name     string
+		schedule string
+		lastRun  string
+		tick     string
+		want     bool
+	}{
+		{name: "same daily tick on another replica", schedule: "0 3 * * *", lastRun: "2026-10-19 03:00:00", tick: "2026-10-19 03:00:00", want: true},
+		{name: "next daily tick", schedule: "0 3 * * *", lastRun: "2026-10-18 03:00:00", tick: "2026-10-19 03:00:00", want: false},
+		{name: "missed ticks", schedule: "0 3 * * *", lastRun: "2026-10-10 03:00:00", tick: "2026-10-19 03:00:00", want: false},
+		{name: "interval tick of a replica started later", schedule: "@every 1h", lastRun: "2026-10-19 10:00:00", tick: "2026-10-19 10:20:00", want: true},
+		{name: "next interval tick", schedule: "@every 1h", lastRun: "2026-10-19 10:00:00", tick: "2026-10-19 11:00:00", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := cron.ParseStandard(tt.schedule)
+			if err != nil {
+				t.Fatalf("ParseStandard: %v", err)
+			}
+			if got := ranThisTick(schedule, at(tt.lastRun), at(tt.tick)); got != tt.want {
+				t.Errorf("ranThisTick = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddRejectsBadSchedule(t *testing.T) {
+	s := New(nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := s.Add(Job{Name: "purge", Schedule: "every day"}); err == nil {
+		t.Error("Add accepted an unparsable schedule")
+	}
+}
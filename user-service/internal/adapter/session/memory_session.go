@@ -0,0 +1,105 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/port"
+)
+
+type memorySession struct {
+	record    sessionRecord
+	expiresAt time.Time
+}
+
+// MemorySessionStore keeps sessions in process memory. It is meant for unit
+// tests and single instance local development.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]memorySession
+}
+
+// Create implements port.SessionStoreInterface.
+func (m *MemorySessionStore) Create(ctx context.Context, session entity.SessionEntity) error {
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[session.Token] = memorySession{
+		record:    newSessionRecord(session),
+		expiresAt: time.Now().Add(m.ttl),
+	}
+	return nil
+}
+
+// Get implements port.SessionStoreInterface.
+func (m *MemorySessionStore) Get(ctx context.Context, token string) (*entity.SessionEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.sessions[token]
+	if !ok {
+		return nil, port.ErrSessionNotFound
+	}
+	if time.Now().After(stored.expiresAt) {
+		delete(m.sessions, token)
+		return nil, port.ErrSessionNotFound
+	}
+
+	stored.expiresAt = time.Now().Add(m.ttl)
+	m.sessions[token] = stored
+
+	return stored.record.toEntity(stored.expiresAt), nil
+}
+
+// Delete implements port.SessionStoreInterface.
+func (m *MemorySessionStore) Delete(ctx context.Context, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, token)
+	return nil
+}
+
+// DeleteByUserID implements port.SessionStoreInterface.
+func (m *MemorySessionStore) DeleteByUserID(ctx context.Context, userID int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for token, stored := range m.sessions {
+		if stored.record.UserID == userID {
+			delete(m.sessions, token)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// DeleteExpired implements port.SessionStoreInterface.
+func (m *MemorySessionStore) DeleteExpired(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	now := time.Now()
+	for token, stored := range m.sessions {
+		if now.After(stored.expiresAt) {
+			delete(m.sessions, token)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func NewMemorySessionStore(ttl time.Duration) *MemorySessionStore {
+	return &MemorySessionStore{
+		ttl:      ttl,
+		sessions: make(map[string]memorySession),
+	}
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+	"user-service/internal/adapter/repository"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/model"
+	"user-service/internal/core/port"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresSessionStore struct {
+	log *slog.Logger
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// Create implements port.SessionStoreInterface.
+func (p *postgresSessionStore) Create(ctx context.Context, session entity.SessionEntity) error {
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+
+	data, err := json.Marshal(newSessionRecord(session))
+	if err != nil {
+		p.log.ErrorContext(ctx, "failed to encode session", "op", "Create", "error", err)
+		return err
+	}
+
+	modelSession := model.Session{
+		Token:     session.Token,
+		UserID:    session.UserID,
+		Data:      string(data),
+		ExpiresAt: time.Now().Add(p.ttl),
+	}
+
+	if err := repository.Conn(ctx, p.db).Create(&modelSession).Error; err != nil {
+		p.log.ErrorContext(ctx, "failed to store session", "op", "Create", "user_id", session.UserID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// Get implements port.SessionStoreInterface. The lookup and the expiry bump
+// happen in a single UPDATE ... RETURNING so expired rows are never revived.
+func (p *postgresSessionStore) Get(ctx context.Context, token string) (*entity.SessionEntity, error) {
+	modelSession := model.Session{}
+	expiresAt := time.Now().Add(p.ttl)
+
+	result := repository.Conn(ctx, p.db).Model(&modelSession).
+		Clauses(clause.Returning{}).
+		Where("token = ? AND expires_at > ?", token, time.Now()).
+		Updates(map[string]interface{}{"expires_at": expiresAt, "updated_at": time.Now()})
+	if result.Error != nil {
+		p.log.ErrorContext(ctx, "failed to read session", "op", "Get", "error", result.Error)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, port.ErrSessionNotFound
+	}
+
+	record := sessionRecord{}
+	if err := json.Unmarshal([]byte(modelSession.Data), &record); err != nil {
+		p.log.ErrorContext(ctx, "failed to decode session", "op", "Get", "error", err)
+		return nil, err
+	}
+
+	return record.toEntity(expiresAt), nil
+}
+
+// Delete implements port.SessionStoreInterface.
+func (p *postgresSessionStore) Delete(ctx context.Context, token string) error {
+	if err := repository.Conn(ctx, p.db).Where("token = ?", token).Delete(&model.Session{}).Error; err != nil {
+		p.log.ErrorContext(ctx, "failed to delete session", "op", "Delete", "error", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteByUserID implements port.SessionStoreInterface.
+func (p *postgresSessionStore) DeleteByUserID(ctx context.Context, userID int64) (int, error) {
+	result := repository.Conn(ctx, p.db).Where("user_id = ?", userID).Delete(&model.Session{})
+	if result.Error != nil {
+		p.log.ErrorContext(ctx, "failed to delete sessions", "op", "DeleteByUserID", "user_id", userID, "error", result.Error)
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+// DeleteExpired implements port.SessionStoreInterface.
+func (p *postgresSessionStore) DeleteExpired(ctx context.Context) (int, error) {
+	result := repository.Conn(ctx, p.db).Where("expires_at <= ?", time.Now()).Delete(&model.Session{})
+	if result.Error != nil {
+		p.log.ErrorContext(ctx, "failed to delete expired sessions", "op", "DeleteExpired", "error", result.Error)
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+func NewPostgresSessionStore(log *slog.Logger, db *gorm.DB, ttl time.Duration) port.SessionStoreInterface {
+	return &postgresSessionStore{
+		log: log,
+		db:  db,
+		ttl: ttl,
+	}
+}
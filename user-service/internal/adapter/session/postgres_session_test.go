@@ -0,0 +1,111 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"user-service/internal/adapter/repository"
+	"user-service/internal/core/domain/entity"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// recordingDriver is a database/sql connector that accepts every statement,
+// returns no rows and records what ran, transaction boundaries included.
+type recordingDriver struct {
+	mu   sync.Mutex
+	log  []string
+	conn int
+}
+
+func (d *recordingDriver) record(entry string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, entry)
+}
+
+func (d *recordingDriver) statements() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.log...)
+}
+
+func (d *recordingDriver) Connect(context.Context) (driver.Conn, error) { return recordingConn{d}, nil }
+func (d *recordingDriver) Driver() driver.Driver                        { return nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c recordingConn) Close() error { return nil }
+func (c recordingConn) Begin() (driver.Tx, error) {
+	c.d.record("BEGIN")
+	return recordingTx(c), nil
+}
+
+func (c recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.record(query)
+	return emptyRows{}, nil
+}
+
+type recordingTx struct{ d *recordingDriver }
+
+func (t recordingTx) Commit() error   { t.d.record("COMMIT"); return nil }
+func (t recordingTx) Rollback() error { t.d.record("ROLLBACK"); return nil }
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{"id"} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestPostgresSessionStoreJoinsUnitOfWork(t *testing.T) {
+	rec := &recordingDriver{}
+	sqlDB := sql.OpenDB(rec)
+	defer sqlDB.Close()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := NewPostgresSessionStore(log, db, time.Hour)
+	unitOfWork := repository.NewUnitOfWork(db, log)
+
+	errAbort := errors.New("abort")
+	err = unitOfWork.Do(context.Background(), func(ctx context.Context) error {
+		if err := store.Create(ctx, entity.SessionEntity{Token: "token", UserID: 1}); err != nil {
+			return err
+		}
+		if _, err := store.DeleteByUserID(ctx, 1); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Do = %v, want the abort error", err)
+	}
+
+	got := rec.statements()
+	if len(got) != 4 || got[0] != "BEGIN" || got[3] != "ROLLBACK" {
+		t.Fatalf("statements = %q, want the session writes between BEGIN and ROLLBACK", got)
+	}
+	if !strings.HasPrefix(got[1], `INSERT INTO "sessions"`) || !strings.HasPrefix(got[2], `DELETE FROM "sessions"`) {
+		t.Errorf("statements = %q, want the insert and delete inside the transaction", got)
+	}
+}
@@ -0,0 +1,152 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/port"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	redisKeyPrefix = "session:"
+	// redisUserKeyPrefix keys the set of tokens issued to a user, used to
+	// revoke all of them. It has no TTL; tokens of expired sessions are
+	// pruned whenever the user gets a new one.
+	redisUserKeyPrefix = "session:user:"
+)
+
+type redisSessionStore struct {
+	log    *slog.Logger
+	client redis.UniversalClient
+	ttl    time.Duration
+}
+
+// Create implements port.SessionStoreInterface.
+func (r *redisSessionStore) Create(ctx context.Context, session entity.SessionEntity) error {
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+
+	data, err := json.Marshal(newSessionRecord(session))
+	if err != nil {
+		r.log.ErrorContext(ctx, "failed to encode session", "op", "Create", "error", err)
+		return err
+	}
+
+	userKey := redisUserKey(session.UserID)
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, redisKeyPrefix+session.Token, data, r.ttl)
+		pipe.SAdd(ctx, userKey, session.Token)
+		return nil
+	})
+	if err != nil {
+		r.log.ErrorContext(ctx, "failed to store session", "op", "Create", "user_id", session.UserID, "error", err)
+		return err
+	}
+
+	if err := r.pruneUserIndex(ctx, userKey); err != nil {
+		r.log.WarnContext(ctx, "failed to prune user session index", "op", "Create", "user_id", session.UserID, "error", err)
+	}
+	return nil
+}
+
+// Get implements port.SessionStoreInterface. GETEX reads the session and
+// refreshes its TTL in one round trip.
+func (r *redisSessionStore) Get(ctx context.Context, token string) (*entity.SessionEntity, error) {
+	data, err := r.client.GetEx(ctx, redisKeyPrefix+token, r.ttl).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, port.ErrSessionNotFound
+		}
+		r.log.ErrorContext(ctx, "failed to read session", "op", "Get", "error", err)
+		return nil, err
+	}
+
+	record := sessionRecord{}
+	if err := json.Unmarshal(data, &record); err != nil {
+		r.log.ErrorContext(ctx, "failed to decode session", "op", "Get", "error", err)
+		return nil, err
+	}
+
+	return record.toEntity(time.Now().Add(r.ttl)), nil
+}
+
+// Delete implements port.SessionStoreInterface.
+func (r *redisSessionStore) Delete(ctx context.Context, token string) error {
+	if err := r.client.Del(ctx, redisKeyPrefix+token).Err(); err != nil {
+		r.log.ErrorContext(ctx, "failed to delete session", "op", "Delete", "error", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteByUserID implements port.SessionStoreInterface.
+func (r *redisSessionStore) DeleteByUserID(ctx context.Context, userID int64) (int, error) {
+	userKey := redisUserKey(userID)
+	tokens, err := r.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		r.log.ErrorContext(ctx, "failed to read user sessions", "op", "DeleteByUserID", "user_id", userID, "error", err)
+		return 0, err
+	}
+
+	deleted := 0
+	for _, token := range tokens {
+		n, err := r.client.Del(ctx, redisKeyPrefix+token).Result()
+		if err != nil {
+			r.log.ErrorContext(ctx, "failed to delete session", "op", "DeleteByUserID", "user_id", userID, "error", err)
+			return deleted, err
+		}
+		deleted += int(n)
+	}
+
+	if err := r.client.Del(ctx, userKey).Err(); err != nil {
+		r.log.ErrorContext(ctx, "failed to delete user session index", "op", "DeleteByUserID", "user_id", userID, "error", err)
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+// DeleteExpired implements port.SessionStoreInterface. Redis expires session
+// keys by their TTL, so there is nothing to do.
+func (r *redisSessionStore) DeleteExpired(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// pruneUserIndex drops tokens whose session has expired from a user index.
+func (r *redisSessionStore) pruneUserIndex(ctx context.Context, userKey string) error {
+	tokens, err := r.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		exists, err := r.client.Exists(ctx, redisKeyPrefix+token).Result()
+		if err != nil {
+			return err
+		}
+		if exists == 0 {
+			if err := r.client.SRem(ctx, userKey, token).Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func redisUserKey(userID int64) string {
+	return redisUserKeyPrefix + strconv.FormatInt(userID, 10)
+}
+
+func NewRedisSessionStore(log *slog.Logger, client redis.UniversalClient, ttl time.Duration) port.SessionStoreInterface {
+	return &redisSessionStore{
+		log:    log,
+		client: client,
+		ttl:    ttl,
+	}
+}
@@ -0,0 +1,76 @@
+package session
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+	"user-service/config"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/port"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+const (
+	DriverRedis    = "redis"
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+
+	defaultTTL = 23 * time.Hour
+)
+
+// NewSessionStore returns the session store selected by SESSION_DRIVER.
+func NewSessionStore(cfg *config.Config, log *slog.Logger, redisClient redis.UniversalClient, db *gorm.DB) (port.SessionStoreInterface, error) {
+	ttl := cfg.Session.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	switch cfg.Session.Driver {
+	case "", DriverRedis:
+		return NewRedisSessionStore(log, redisClient, ttl), nil
+	case DriverPostgres:
+		return NewPostgresSessionStore(log, db, ttl), nil
+	case DriverMemory:
+		return NewMemorySessionStore(ttl), nil
+	default:
+		return nil, fmt.Errorf("unknown session driver %q", cfg.Session.Driver)
+	}
+}
+
+// sessionRecord is the JSON document every backend stores for a session.
+type sessionRecord struct {
+	Token     string    `json:"token"`
+	UserID    int64     `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	RoleName  string    `json:"role_name"`
+	LoggedIn  bool      `json:"logged_in"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newSessionRecord(session entity.SessionEntity) sessionRecord {
+	return sessionRecord{
+		Token:     session.Token,
+		UserID:    session.UserID,
+		Name:      session.Name,
+		Email:     session.Email,
+		RoleName:  session.RoleName,
+		LoggedIn:  session.LoggedIn,
+		CreatedAt: session.CreatedAt,
+	}
+}
+
+func (r sessionRecord) toEntity(expiresAt time.Time) *entity.SessionEntity {
+	return &entity.SessionEntity{
+		Token:     r.Token,
+		UserID:    r.UserID,
+		Name:      r.Name,
+		Email:     r.Email,
+		RoleName:  r.RoleName,
+		LoggedIn:  r.LoggedIn,
+		CreatedAt: r.CreatedAt,
+		ExpiresAt: expiresAt,
+	}
+}
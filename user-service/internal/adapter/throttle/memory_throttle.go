@@ -0,0 +1,37 @@
+// Package throttle limits how often an action may happen per key.
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryThrottle keeps blocked keys in process memory. It is meant for unit
+// tests and single instance local development.
+type MemoryThrottle struct {
+	mu      sync.Mutex
+	blocked map[string]time.Time
+}
+
+// Allow implements port.ThrottleInterface.
+func (m *MemoryThrottle) Allow(ctx context.Context, key string, window time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if until, ok := m.blocked[key]; ok && now.Before(until) {
+		return false, nil
+	}
+	for blocked, until := range m.blocked {
+		if !now.Before(until) {
+			delete(m.blocked, blocked)
+		}
+	}
+	m.blocked[key] = now.Add(window)
+	return true, nil
+}
+
+func NewMemoryThrottle() *MemoryThrottle {
+	return &MemoryThrottle{blocked: map[string]time.Time{}}
+}
@@ -0,0 +1,32 @@
+package throttle
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"user-service/internal/core/port"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const redisKeyPrefix = "throttle:"
+
+type redisThrottle struct {
+	log    *slog.Logger
+	client redis.UniversalClient
+}
+
+// Allow implements port.ThrottleInterface. SET NX only succeeds for the first
+// caller while the key lives, and EX lets the key expire with the window.
+func (r *redisThrottle) Allow(ctx context.Context, key string, window time.Duration) (bool, error) {
+	allowed, err := r.client.SetNX(ctx, redisKeyPrefix+key, 1, window).Result()
+	if err != nil {
+		r.log.ErrorContext(ctx, "failed to take throttle key", "op", "Allow", "error", err)
+		return false, err
+	}
+	return allowed, nil
+}
+
+func NewRedisThrottle(log *slog.Logger, client redis.UniversalClient) port.ThrottleInterface {
+	return &redisThrottle{log: log, client: client}
+}
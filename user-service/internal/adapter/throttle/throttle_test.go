@@ -0,0 +1,68 @@
+package throttle
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"user-service/internal/core/port"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestThrottle(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	throttles := map[string]struct {
+		throttle port.ThrottleInterface
+		// elapse moves the throttle's clock forward
+		elapse func(d time.Duration)
+	}{
+		"redis":  {NewRedisThrottle(slog.New(slog.NewTextHandler(io.Discard, nil)), client), server.FastForward},
+		"memory": {NewMemoryThrottle(), time.Sleep},
+	}
+
+	for name, tt := range throttles {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := name + ":siti"
+			window := 50 * time.Millisecond
+
+			// concurrent callers race for the key; exactly one wins
+			var allowed atomic.Int32
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					ok, err := tt.throttle.Allow(ctx, key, window)
+					if err != nil {
+						t.Errorf("Allow: %v", err)
+					}
+					if ok {
+						allowed.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+			if n := allowed.Load(); n != 1 {
+				t.Fatalf("%d concurrent callers allowed, want 1", n)
+			}
+
+			if ok, _ := tt.throttle.Allow(ctx, name+":budi", window); !ok {
+				t.Error("another key is blocked too")
+			}
+
+			tt.elapse(2 * window)
+			if ok, _ := tt.throttle.Allow(ctx, key, window); !ok {
+				t.Error("key still blocked after the window")
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// noConnPool fails every statement; the tests run GORM in dry run mode, so
+// nothing reaches it.
+type noConnPool struct{}
+
+var errNoDatabase = errors.New("no database in tests")
+
+func (noConnPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errNoDatabase
+}
+
+func (noConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, errNoDatabase
+}
+
+func (noConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errNoDatabase
+}
+
+func (noConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+type user struct {
+	ID    int64
+	Email string
+}
+
+func TestGormPluginStartsChildSpans(t *testing.T) {
+	recorder := recordSpans(t)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: noConnPool{}}), &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.Use(GormPlugin{}); err != nil {
+		t.Fatalf("use: %v", err)
+	}
+
+	ctx, request := startRequestSpan(t)
+	db = db.WithContext(ctx)
+	db.Create(&user{Email: "siti@example.com"})
+	db.Where("email = ?", "siti@example.com").Find(&[]user{})
+	db.Model(&user{ID: 1}).Update("email", "budi@example.com")
+	db.Delete(&user{ID: 1})
+	request.End()
+
+	for _, operation := range []string{"create", "query", "update", "delete"} {
+		span := assertChildSpan(t, recorder, request, "gorm "+operation)
+		if !hasAttribute(span.Attributes(), attribute.String("db.sql.table", "users")) {
+			t.Errorf("gorm %s attributes = %v, want db.sql.table=users", operation, span.Attributes())
+		}
+	}
+
+	query := assertChildSpan(t, recorder, request, "gorm query")
+	want := attribute.String("db.statement", `SELECT * FROM "users" WHERE email = $1`)
+	if !hasAttribute(query.Attributes(), want) {
+		t.Errorf("gorm query attributes = %v, want the statement without its arguments", query.Attributes())
+	}
+}
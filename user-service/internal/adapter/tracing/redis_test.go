@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestRedisHookStartsChildSpans(t *testing.T) {
+	recorder := recordSpans(t)
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	client.AddHook(RedisHook{})
+
+	ctx, request := startRequestSpan(t)
+	if err := client.Set(ctx, "session:1", "user:1", 0).Err(); err != nil {
+		t.Fatalf("SET: %v", err)
+	}
+	if err := client.Get(ctx, "session:missing").Err(); err != redis.Nil {
+		t.Fatalf("GET missing key = %v, want redis.Nil", err)
+	}
+	pipe := client.Pipeline()
+	pipe.Incr(ctx, "counter")
+	pipe.Expire(ctx, "counter", 0)
+	if _, err := pipe.Exec(ctx); err != nil {
+		t.Fatalf("pipeline: %v", err)
+	}
+	request.End()
+
+	set := assertChildSpan(t, recorder, request, "redis set")
+	if !hasAttribute(set.Attributes(), attribute.String("db.system", "redis")) {
+		t.Errorf("redis set attributes = %v, want db.system=redis", set.Attributes())
+	}
+	get := assertChildSpan(t, recorder, request, "redis get")
+	if get.Status().Code == codes.Error {
+		t.Error("a missing key is recorded as an error")
+	}
+	pipeline := assertChildSpan(t, recorder, request, "redis pipeline")
+	if !hasAttribute(pipeline.Attributes(), attribute.Int("db.redis.num_cmd", 2)) {
+		t.Errorf("redis pipeline attributes = %v, want db.redis.num_cmd=2", pipeline.Attributes())
+	}
+}
+
+func hasAttribute(attributes []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, kv := range attributes {
+		if kv == want {
+			return true
+		}
+	}
+	return false
+}
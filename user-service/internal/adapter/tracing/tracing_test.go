@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a tracer provider recording every ended span until the
+// test finishes.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+// startRequestSpan starts the span a request handler would have open.
+func startRequestSpan(t *testing.T) (context.Context, trace.Span) {
+	t.Helper()
+	return Tracer().Start(context.Background(), "GET /api/v1/profile", trace.WithSpanKind(trace.SpanKindServer))
+}
+
+// assertChildSpan fails unless recorder holds exactly one span named name,
+// started as a child of parent.
+func assertChildSpan(t *testing.T, recorder *tracetest.SpanRecorder, parent trace.Span, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	var found []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			found = append(found, span)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("got %d %q spans, want 1", len(found), name)
+	}
+
+	span := found[0]
+	want := parent.SpanContext()
+	if span.Parent().SpanID() != want.SpanID() || span.SpanContext().TraceID() != want.TraceID() {
+		t.Errorf("%q parent = %s/%s, want the request span %s/%s", name,
+			span.SpanContext().TraceID(), span.Parent().SpanID(), want.TraceID(), want.SpanID())
+	}
+	if span.SpanKind() != trace.SpanKindClient {
+		t.Errorf("%q kind = %s, want client", name, span.SpanKind())
+	}
+	return span
+}
+
+func TestTraceID(t *testing.T) {
+	recordSpans(t)
+
+	if got := TraceID(context.Background()); got != "" {
+		t.Errorf("TraceID without a span = %q, want empty", got)
+	}
+
+	ctx, span := startRequestSpan(t)
+	defer span.End()
+	if got, want := TraceID(ctx), span.SpanContext().TraceID().String(); got != want {
+		t.Errorf("TraceID = %q, want %q", got, want)
+	}
+}
@@ -2,72 +2,353 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 	"user-service/config"
+	"user-service/database/migrations"
+	"user-service/internal/adapter"
+	"user-service/internal/adapter/breach"
 	"user-service/internal/adapter/handler"
+	"user-service/internal/adapter/handler/openapi"
+	"user-service/internal/adapter/message"
+	"user-service/internal/adapter/metrics"
 	"user-service/internal/adapter/repository"
+	"user-service/internal/adapter/rpc"
+	"user-service/internal/adapter/scheduler"
+	"user-service/internal/adapter/session"
+	"user-service/internal/adapter/throttle"
+	"user-service/internal/adapter/tracing"
 	"user-service/internal/core/service"
+	"user-service/utils/i18n"
+	"user-service/utils/logger"
 	"user-service/utils/validator"
 
-	"github.com/go-playground/validator/v10/translations/en"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"github.com/labstack/gommon/log"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
-func RunServer() {
-	cfg := config.NewConfig()
-	db, err := cfg.ConnectionPostgres()
+// secretRotationTimeout bounds the connection attempt made with a rotated
+// password.
+const secretRotationTimeout = 10 * time.Second
+
+func RunServer(cfg *config.Config) error {
+	log := cfg.NewLogger()
+	slog.SetDefault(log)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("setup tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	if cfg.Psql.AutoMigrate {
+		if err := migrateUp(cfg, log); err != nil {
+			return err
+		}
+	}
+
+	db, err := cfg.ConnectionPostgres(log)
+	if err != nil {
+		return err
+	}
+
+	if err := db.DB.Use(metrics.GormPlugin{}); err != nil {
+		return fmt.Errorf("register gorm metrics: %w", err)
+	}
+	if err := db.DB.Use(tracing.GormPlugin{}); err != nil {
+		return fmt.Errorf("register gorm tracing: %w", err)
+	}
+	if sqlDB, err := db.DB.DB(); err == nil {
+		if err := metrics.RegisterDBStats(sqlDB, cfg.Psql.DBName); err != nil {
+			log.Error("failed to register db stats", "error", err)
+		}
+	}
+
+	userRepo := repository.NewUserRepository(db.DB, log)
+	tokenRepo := repository.NewVerificationTokenRepository(db.DB, log)
+	unitOfWork := repository.NewUnitOfWork(db.DB, log)
+
+	redisClient, err := cfg.NewRedisClient(context.Background())
+	if err != nil {
+		return err
+	}
+	defer redisClient.Close()
+	redisClient.AddHook(metrics.RedisHook{})
+	redisClient.AddHook(tracing.RedisHook{})
+
+	sessionStore, err := session.NewSessionStore(cfg, log, redisClient, db.DB)
+	if err != nil {
+		return err
+	}
+
+	publisher, err := message.NewPublisher(cfg, log)
 	if err != nil {
-		log.Fatalf("[RunServer-1] %v", err)
-		return
+		return err
 	}
+	defer publisher.Close()
 
-	userRepo := repository.NewUserRepository(db.DB)
-	tokenRepo := repository.NewVerificationTokenRepository(db.DB)
+	rotators := map[string]config.PasswordRotator{
+		"DATABASE_PASSWORD": db,
+		"REDIS_PASSWORD":    redisClient,
+	}
+	if rotator, ok := publisher.(config.PasswordRotator); ok {
+		rotators["RABBITMQ_PASSWORD"] = rotator
+	}
+	publisher = metrics.InstrumentPublisher(publisher, cfg.Message.Broker)
 
 	jwtService := service.NewJwtService(cfg)
-	userService := service.NewUserService(userRepo, cfg, jwtService, tokenRepo)
+
+	breachProvider, err := breach.NewProvider(cfg, log)
+	if err != nil {
+		return err
+	}
+	passwordService, err := service.NewPasswordService(log, cfg, breachProvider)
+	if err != nil {
+		return err
+	}
+
+	userService := service.NewUserService(log, userRepo, cfg, jwtService, tokenRepo, publisher, sessionStore, unitOfWork, passwordService, throttle.NewRedisThrottle(log, redisClient))
+
+	if cfg.Scheduler.Enabled {
+		maintenanceService := service.NewMaintenanceService(log, cfg, userRepo, tokenRepo, sessionStore, publisher, unitOfWork)
+		jobs, err := startScheduler(cfg, log, db, maintenanceService)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			jobs.Stop(ctx)
+		}()
+	}
+
+	// request contexts derive from requestCtx, so queries still running when
+	// the shutdown timeout hits are cancelled instead of left behind
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 
 	e := echo.New()
+	e.HideBanner = true
+	e.Server.BaseContext = func(net.Listener) context.Context { return requestCtx }
+	e.HTTPErrorHandler = handler.NewErrorHandler(log)
+	e.Use(logger.RequestIDMiddleware())
+	e.Use(metrics.Middleware())
+	e.Use(otelecho.Middleware(cfg.Tracing.ServiceName))
+	// inside otelecho so the request log carries the trace id
+	e.Use(logger.RequestLogMiddleware(log))
+	e.Use(i18n.Middleware(cfg.App.DefaultLanguage))
 	e.Use(middleware.CORS())
 	// e.Use(sessions)
 	// e.Group("/api")
 
-	customValidator := validator.NewValidator()
-	en.RegisterDefaultTranslations(customValidator.Validator, customValidator.Translator)
-	e.Validator = customValidator
+	e.Validator = validator.NewValidator()
 
 	e.GET("/api/check", func(c echo.Context) error {
 		return c.String(http.StatusOK, "OK")
 	})
 
-	handler.NewUserHandler(e, userService, cfg)
+	checks := []service.HealthCheck{
+		{Name: "postgres", Check: db.Ping},
+		{Name: "redis", Check: func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}},
+		{Name: "message_broker", Check: publisher.Ping},
+	}
+
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	if check, ok := watchSecrets(watchCtx, cfg, log, jwtService, rotators); ok {
+		checks = append(checks, check)
+	}
+
+	healthService := service.NewHealthService(cfg.Health.CheckTimeout, cfg.Health.CacheTTL, checks...)
+	handler.NewHealthHandler(e, log, healthService)
+	e.GET("/metrics", metrics.Handler())
 
+	mid := adapter.NewMiddlewareAdapter(cfg, log, sessionStore)
+	handler.NewUserHandler(e, userService, mid)
+	openapi.Register(e, handler.APIPrefix, cfg.App.AppEnv == config.EnvDevelopment)
+	if cfg.App.AppEnv == config.EnvDevelopment {
+		if err := checkOpenAPI(e); err != nil {
+			log.Warn("routes and openapi spec differ, run sayur-api openapi check", "error", err)
+		}
+	}
+
+	serverErr := make(chan error, 2)
 	go func() {
-		if cfg.App.AppPort == "" {
-			cfg.App.AppPort = os.Getenv("APP_PORT")
+		log.Info("starting http server", "port", cfg.App.AppPort)
+		err := e.Start(":" + cfg.App.AppPort)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- fmt.Errorf("start http server: %w", err)
 		}
+	}()
 
-		err = e.Start(":" + cfg.App.AppPort)
+	var grpcServer *grpc.Server
+	var grpcHealth *health.Server
+	if cfg.GRPC.Enabled {
+		grpcServer, grpcHealth, err = rpc.NewServer(cfg, log, userService)
 		if err != nil {
-			log.Fatalf("[RunServer-2] %v", err)
+			return err
 		}
-	}()
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+		if err != nil {
+			return fmt.Errorf("listen grpc: %w", err)
+		}
+		go func() {
+			log.Info("starting grpc server", "port", cfg.GRPC.Port, "tls", cfg.GRPC.CertFile != "", "mtls", cfg.GRPC.ClientCAFile != "")
+			if err := grpcServer.Serve(listener); err != nil {
+				serverErr <- fmt.Errorf("start grpc server: %w", err)
+			}
+		}()
+	}
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 	signal.Notify(quit, syscall.SIGTERM)
 
-	<-quit
+	select {
+	case err := <-serverErr:
+		return err
+	case <-quit:
+	}
+
+	// fail readiness first and give load balancers time to drain traffic
+	healthService.SetShuttingDown()
+	if grpcHealth != nil {
+		grpcHealth.Shutdown()
+	}
+	log.Info("readiness failing, draining traffic", "drain_delay", cfg.Health.DrainDelay.String())
+	time.Sleep(cfg.Health.DrainDelay)
 
-	log.Print("[RunServer-3] Shutting down server of 5 seconds...")
+	log.Info("shutting down http server", "timeout", "5s")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	e.Shutdown(ctx)
+	if grpcServer != nil {
+		stopGRPC(grpcServer, cfg.GRPC.ShutdownTimeout, log)
+	}
+	if err := e.Shutdown(ctx); err != nil {
+		log.Warn("http shutdown timed out, cancelling open requests", "error", err)
+		return err
+	}
+	return nil
+}
+
+// startScheduler schedules the cleanup jobs. The unverified user jobs are
+// left out when their day count is zero.
+func startScheduler(cfg *config.Config, log *slog.Logger, db *config.Postgres, maintenance service.MaintenanceServiceInterface) (*scheduler.Scheduler, error) {
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get database connection for scheduler: %w", err)
+	}
+
+	jobs := []scheduler.Job{
+		{Name: "purge_stale_tokens", Schedule: cfg.Scheduler.TokenCleanupSchedule, Run: maintenance.PurgeStaleTokens},
+		{Name: "purge_expired_sessions", Schedule: cfg.Scheduler.SessionCleanupSchedule, Run: maintenance.PurgeExpiredSessions},
+	}
+	if cfg.Scheduler.UnverifiedRemindDays > 0 {
+		jobs = append(jobs, scheduler.Job{Name: "remind_unverified_users", Schedule: cfg.Scheduler.UnverifiedUsersSchedule, Run: maintenance.RemindUnverifiedUsers})
+	}
+	if cfg.Scheduler.UnverifiedDeleteDays > 0 {
+		jobs = append(jobs, scheduler.Job{Name: "delete_unverified_users", Schedule: cfg.Scheduler.UnverifiedUsersSchedule, Run: maintenance.DeleteUnverifiedUsers})
+	}
+
+	s := scheduler.New(sqlDB, log)
+	for _, job := range jobs {
+		if err := s.Add(job); err != nil {
+			return nil, err
+		}
+	}
+	s.Start()
+	return s, nil
+}
+
+// stopGRPC lets in-flight calls finish and cuts them off after timeout.
+func stopGRPC(server *grpc.Server, timeout time.Duration, log *slog.Logger) {
+	log.Info("shutting down grpc server", "timeout", timeout.String())
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		log.Warn("grpc graceful stop timed out, closing open calls")
+		server.Stop()
+	}
+}
+
+// migrateUp applies pending migrations before the first connection seeds the
+// database.
+func migrateUp(cfg *config.Config, log *slog.Logger) error {
+	m, err := migrations.New(cfg, log)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil {
+		return fmt.Errorf("migrate up: %w", err)
+	}
+	return nil
+}
+
+func checkOpenAPI(e *echo.Echo) error {
+	doc, err := openapi.Load()
+	if err != nil {
+		return err
+	}
+	return openapi.Check(doc, e.Routes(), handler.APIPrefix)
+}
+
+// watchSecrets keeps polling the secret provider when one is configured and
+// returns the readiness check reporting rotated secrets that could not be
+// applied. The JWT key is swapped in place and the clients in rotators
+// reconnect with their rotated password, keeping the old one until the new
+// one is accepted.
+func watchSecrets(ctx context.Context, cfg *config.Config, log *slog.Logger, jwtService service.JwtServiceInterface, rotators map[string]config.PasswordRotator) (service.HealthCheck, bool) {
+	provider, err := cfg.NewSecretProvider()
+	if err != nil || provider == nil || cfg.Secrets.RefreshInterval <= 0 {
+		return service.HealthCheck{}, false
+	}
+
+	watcher := config.NewSecretWatcher(log, provider, cfg.Secrets.RefreshInterval, cfg.SecretValues)
+	go watcher.Watch(ctx, func(ctx context.Context, key, value string) error {
+		if key == "JWT_SECRET_KEY" {
+			jwtService.RotateSecretKey(value)
+			log.Info("jwt secret key rotated")
+			return nil
+		}
+
+		rotator, ok := rotators[key]
+		if !ok {
+			if config.IsSecretKey(key) {
+				log.Warn("secret rotated, it takes effect on the next start", "key", key)
+			}
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, secretRotationTimeout)
+		defer cancel()
+		if err := rotator.RotatePassword(ctx, value); err != nil {
+			return err
+		}
+		log.Info("secret rotated, reconnected with the new value", "key", key)
+		return nil
+	})
+
+	return service.HealthCheck{Name: "secrets", Check: watcher.Check}, true
 }
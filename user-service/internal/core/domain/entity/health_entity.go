@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+type HealthEntity struct {
+	Status string
+	Checks []HealthCheckEntity
+}
+
+type HealthCheckEntity struct {
+	Name      string
+	Status    string
+	Error     string
+	Duration  time.Duration
+	CheckedAt time.Time
+}
@@ -0,0 +1,7 @@
+package entity
+
+type NotificationEntity struct {
+	Email            string
+	Message          string
+	NotificationType string
+}
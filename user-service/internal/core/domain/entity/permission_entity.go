@@ -0,0 +1,33 @@
+package entity
+
+const (
+	RoleSuperAdmin = "Super Admin"
+	RoleCustomer   = "Customer"
+)
+
+// Permissions checked by other services through CheckPermission, named
+// resource:action.
+const (
+	PermissionProfileRead  = "profile:read"
+	PermissionProfileWrite = "profile:write"
+	PermissionOrderRead    = "order:read"
+	PermissionOrderCreate  = "order:create"
+	PermissionUserRead     = "user:read"
+	PermissionUserWrite    = "user:write"
+	PermissionProductWrite = "product:write"
+)
+
+// PermissionAll grants every permission.
+const PermissionAll = "*"
+
+// RolePermissions lists what each role may do. Roles missing here get no
+// permission at all.
+var RolePermissions = map[string][]string{
+	RoleSuperAdmin: {PermissionAll},
+	RoleCustomer: {
+		PermissionProfileRead,
+		PermissionProfileWrite,
+		PermissionOrderRead,
+		PermissionOrderCreate,
+	},
+}
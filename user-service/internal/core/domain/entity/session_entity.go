@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type SessionEntity struct {
+	Token     string
+	UserID    int64
+	Name      string
+	Email     string
+	RoleName  string
+	LoggedIn  bool
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
@@ -1,16 +1,21 @@
 package entity
 
 type UserEntity struct {
-	ID         int64
-	Name       string
-	Email      string
-	Password   string
-	RoleName   string
-	Address    string
-	Lat        string
-	Lng        string
-	Phone      string
-	Photo      string
-	IsVerified bool
-	Token      string
+	ID          int64
+	Name        string
+	Email       string
+	Password    string
+	RoleName    string
+	Address     string
+	Lat         string
+	Lng         string
+	Phone       string
+	Photo       string
+	IsVerified  bool
+	Language    string
+	IsSuspended bool
+	Token       string
+	// Client is the app the request came from (web, android or ios); it
+	// picks the kind of link mailed back.
+	Client string
 }
@@ -2,6 +2,11 @@ package entity
 
 import "time"
 
+const (
+	TokenTypeEmailVerification = "email_verification"
+	TokenTypeResetPassword     = "reset_password"
+)
+
 type VerificationTokenEntity struct {
 	ID        int64
 	UserID    int64
@@ -0,0 +1,127 @@
+package errs
+
+import (
+	"errors"
+	"strings"
+)
+
+// Code is a stable, machine readable error code returned to API clients.
+type Code string
+
+const (
+	CodeInternal           Code = "internal_error"
+	CodeBadRequest         Code = "bad_request"
+	CodeValidation         Code = "validation_failed"
+	CodeNotFound           Code = "not_found"
+	CodeUnauthorized       Code = "unauthorized"
+	CodeForbidden          Code = "forbidden"
+	CodeConflict           Code = "conflict"
+	CodeUnavailable        Code = "service_unavailable"
+	CodeUserNotFound       Code = "user_not_found"
+	CodeEmailTaken         Code = "email_already_registered"
+	CodeInvalidCredentials Code = "invalid_credentials"
+	CodeTokenMissing       Code = "token_missing"
+	CodeTokenInvalid       Code = "token_invalid"
+	CodeTokenExpired       Code = "token_expired"
+	CodeSessionNotFound    Code = "session_not_found"
+	CodeAccountSuspended   Code = "account_suspended"
+	CodeRoleNotFound       Code = "role_not_found"
+	CodeAccountUnverified  Code = "account_not_verified"
+	CodeTooManyRequests    Code = "too_many_requests"
+	CodeWeakPassword       Code = "weak_password"
+)
+
+var (
+	ErrUserNotFound       = New(CodeUserNotFound, "user not found")
+	ErrEmailTaken         = New(CodeEmailTaken, "email is already registered")
+	ErrInvalidCredentials = New(CodeInvalidCredentials, "email or password is incorrect")
+	ErrTokenMissing       = New(CodeTokenMissing, "missing or invalid token")
+	ErrTokenInvalid       = New(CodeTokenInvalid, "token is invalid")
+	ErrTokenExpired       = New(CodeTokenExpired, "token has expired")
+	ErrSessionNotFound    = New(CodeSessionNotFound, "session not found")
+	ErrAccountSuspended   = New(CodeAccountSuspended, "account is suspended")
+	ErrRoleNotFound       = New(CodeRoleNotFound, "role not found")
+	ErrAccountUnverified  = New(CodeAccountUnverified, "account is not verified")
+	ErrTooManyRequests    = New(CodeTooManyRequests, "too many requests, try again later")
+	ErrWeakPassword       = New(CodeWeakPassword, "password does not meet the password policy")
+)
+
+// FieldError points a validation failure at a single request field.
+type FieldError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message string
+}
+
+// Error is a domain error. Message is safe to show to clients; the wrapped
+// error carries the underlying cause for logs.
+type Error struct {
+	Code    Code
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports errors with the same code as equal, so a wrapped sentinel still
+// matches errors.Is(err, errs.ErrUserNotFound).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// New returns an error with the given code and client facing message.
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Validation returns a validation error listing every failed field.
+func Validation(fields ...FieldError) *Error {
+	return &Error{Code: CodeValidation, Message: "request validation failed", Fields: fields}
+}
+
+// WeakPassword returns a weak password error listing every rule the password
+// breaks. The rule messages are added to the error message, for callers that
+// only print it.
+func WeakPassword(fields ...FieldError) *Error {
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Message)
+	}
+	return &Error{
+		Code:    CodeWeakPassword,
+		Message: ErrWeakPassword.Message + ": " + strings.Join(messages, "; "),
+		Fields:  fields,
+	}
+}
+
+// Wrap attaches a code and client facing message to err.
+func Wrap(err error, code Code, message string) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// WithCause returns a copy of a sentinel error wrapping err.
+func (e *Error) WithCause(err error) *Error {
+	return &Error{Code: e.Code, Message: e.Message, Fields: e.Fields, Err: err}
+}
+
+// CodeOf returns the code of the first domain error in err's chain, or
+// CodeInternal when there is none.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return CodeInternal
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type Session struct {
+	ID        int64  `gorm:"primaryKey"`
+	Token     string `gorm:"uniqueIndex"`
+	UserID    int64  `gorm:"index"`
+	Data      string `gorm:"type:jsonb"`
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
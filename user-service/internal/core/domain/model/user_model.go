@@ -13,8 +13,19 @@ type User struct {
 	Lat        string
 	Lng        string
 	IsVerified bool
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  *time.Time
-	Roles      []Role `gorm:"many2many:user_role"`
+	Language   string
+	// SuspendedAt is set while an operator has suspended the account.
+	SuspendedAt *time.Time
+	// VerificationRemindedAt is set once an unverified user got a reminder.
+	VerificationRemindedAt *time.Time
+	// VerificationRemindAttemptedAt is set when a reminder could not be sent,
+	// so the next attempt waits for the retry interval.
+	VerificationRemindAttemptedAt *time.Time
+	// CreatedByOperator marks accounts an operator created, which are never
+	// deleted for staying unverified.
+	CreatedByOperator bool
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         *time.Time
+	Roles             []Role `gorm:"many2many:user_role"`
 }
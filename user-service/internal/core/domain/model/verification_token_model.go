@@ -2,14 +2,18 @@ package model
 
 import "time"
 
+// VerificationToken stores the SHA-256 hash of the emailed token, never the
+// token itself.
 type VerificationToken struct {
 	ID        int64 `gorm:"primaryKey"`
 	UserID    int64
 	Token     string
 	TokenType string
 	ExpiresAt time.Time
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt *time.Time
-	User      User `gorm:"foreignKey:UserID"`
+	// ConsumedAt is set once the token is used or replaced by a newer one.
+	ConsumedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  *time.Time
+	User       User `gorm:"foreignKey:UserID"`
 }
@@ -0,0 +1,12 @@
+package port
+
+import "context"
+
+// BreachedPasswordProviderInterface looks passwords up in known data breaches
+// with k-anonymity: only the first five hex characters of the password's
+// SHA-1 hash are handed over.
+type BreachedPasswordProviderInterface interface {
+	// Range returns the upper case SHA-1 suffixes, the 35 characters after
+	// prefix, of the breached passwords whose hash starts with prefix.
+	Range(ctx context.Context, prefix string) ([]string, error)
+}
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+	"user-service/internal/core/domain/entity"
+)
+
+// PublisherInterface is the port used by the core to hand notifications to a
+// message broker. NotificationType is used as the queue/topic name.
+type PublisherInterface interface {
+	Publish(ctx context.Context, notification entity.NotificationEntity) error
+	// Ping reports whether the broker is reachable, for readiness checks.
+	Ping(ctx context.Context) error
+	Close() error
+}
@@ -0,0 +1,24 @@
+package port
+
+import (
+	"context"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/errs"
+)
+
+var ErrSessionNotFound = errs.ErrSessionNotFound
+
+// SessionStoreInterface persists sessions keyed by access token. Sessions use
+// a sliding expiry: every successful Get pushes ExpiresAt forward by the
+// store's TTL.
+type SessionStoreInterface interface {
+	Create(ctx context.Context, session entity.SessionEntity) error
+	Get(ctx context.Context, token string) (*entity.SessionEntity, error)
+	Delete(ctx context.Context, token string) error
+	// DeleteByUserID removes every session of a user and returns how many
+	// there were.
+	DeleteByUserID(ctx context.Context, userID int64) (int, error)
+	// DeleteExpired removes expired sessions and returns how many there were.
+	// Stores that expire sessions on their own report zero.
+	DeleteExpired(ctx context.Context) (int, error)
+}
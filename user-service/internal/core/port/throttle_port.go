@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+	"time"
+)
+
+// ThrottleInterface limits how often an action may happen per key, across
+// every replica sharing the store.
+type ThrottleInterface interface {
+	// Allow reports whether the action keyed by key may happen now and, when
+	// it may, blocks the key for window. Checking and blocking are one atomic
+	// step, so concurrent callers cannot both be allowed.
+	Allow(ctx context.Context, key string, window time.Duration) (bool, error)
+}
@@ -0,0 +1,13 @@
+package port
+
+import "context"
+
+// UnitOfWorkInterface runs a group of repository calls atomically. The
+// context handed to fn carries the transaction; repositories called with it
+// join the transaction instead of using their own connection.
+type UnitOfWorkInterface interface {
+	// Do commits when fn returns nil and rolls back otherwise. Called inside
+	// another Do, it runs fn in a nested transaction (a savepoint), so a
+	// failing inner unit does not undo the outer one.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+	"user-service/config"
+	"user-service/internal/adapter/repository"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/errs"
+	"user-service/internal/core/port"
+	"user-service/utils/conv"
+)
+
+// ErrMintInProduction is returned by MintToken in production.
+var ErrMintInProduction = errors.New("minting tokens is disabled in production")
+
+// AdminServiceInterface holds the operator actions behind the admin CLI.
+// Users are addressed by email, verified or not.
+type AdminServiceInterface interface {
+	// CreateUser creates a user with req.RoleName, verified or not as
+	// req.IsVerified says. An empty req.Password is generated, any other
+	// must meet the password policy; the password used is returned.
+	CreateUser(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, string, error)
+	VerifyUser(ctx context.Context, email string) (*entity.UserEntity, error)
+	// ResetPassword sets password, which must meet the password policy, or
+	// a generated one when empty, and signs the user out everywhere. The
+	// password used is returned.
+	ResetPassword(ctx context.Context, email, password string) (string, error)
+	// SetSuspended suspends or reinstates a user. Suspending signs the user
+	// out everywhere.
+	SetSuspended(ctx context.Context, email string, suspended bool) (*entity.UserEntity, error)
+	// AssignRole replaces the role of a user and signs the user out so new
+	// sessions carry the new role.
+	AssignRole(ctx context.Context, email, roleName string) (*entity.UserEntity, error)
+	RevokeSession(ctx context.Context, token string) error
+	RevokeUserSessions(ctx context.Context, email string) (int, error)
+	// MintToken signs in as the user without a password, for debugging.
+	// It refuses to run in production.
+	MintToken(ctx context.Context, email string) (string, error)
+}
+
+type adminService struct {
+	log          *slog.Logger
+	cfg          *config.Config
+	repo         repository.UserRepositoryInterface
+	jwtService   JwtServiceInterface
+	sessionStore port.SessionStoreInterface
+	passwords    PasswordServiceInterface
+}
+
+func (a *adminService) CreateUser(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, string, error) {
+	password := req.Password
+	if password == "" {
+		generated, err := conv.GeneratePassword()
+		if err != nil {
+			return nil, "", err
+		}
+		password = generated
+	} else if err := a.passwords.Validate(ctx, password, req); err != nil {
+		return nil, "", err
+	}
+
+	hashed, err := a.passwords.Hash(password)
+	if err != nil {
+		a.log.ErrorContext(ctx, "failed to hash password", "op", "CreateUser", "error", err)
+		return nil, "", err
+	}
+	req.Password = hashed
+	if req.Language == "" {
+		req.Language = a.cfg.App.DefaultLanguage
+	}
+
+	user, err := a.repo.CreateUser(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+	a.log.InfoContext(ctx, "user created by operator", "op", "CreateUser", "user_id", user.ID, "role", user.RoleName)
+	return user, password, nil
+}
+
+func (a *adminService) VerifyUser(ctx context.Context, email string) (*entity.UserEntity, error) {
+	user, err := a.repo.FindUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsVerified {
+		return user, nil
+	}
+
+	user, err = a.repo.UpdateUserVerified(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	a.log.InfoContext(ctx, "user verified by operator", "op", "VerifyUser", "user_id", user.ID)
+	return user, nil
+}
+
+func (a *adminService) ResetPassword(ctx context.Context, email, password string) (string, error) {
+	user, err := a.repo.FindUserByEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+
+	if password == "" {
+		if password, err = conv.GeneratePassword(); err != nil {
+			return "", err
+		}
+	} else if err := a.passwords.Validate(ctx, password, *user); err != nil {
+		return "", err
+	}
+
+	hashed, err := a.passwords.Hash(password)
+	if err != nil {
+		a.log.ErrorContext(ctx, "failed to hash password", "op", "ResetPassword", "error", err)
+		return "", err
+	}
+	if err := a.repo.UpdatePasswordByID(ctx, entity.UserEntity{ID: user.ID, Password: hashed}); err != nil {
+		return "", err
+	}
+
+	if _, err := a.sessionStore.DeleteByUserID(ctx, user.ID); err != nil {
+		return "", err
+	}
+	a.log.InfoContext(ctx, "password reset by operator", "op", "ResetPassword", "user_id", user.ID)
+	return password, nil
+}
+
+func (a *adminService) SetSuspended(ctx context.Context, email string, suspended bool) (*entity.UserEntity, error) {
+	user, err := a.repo.FindUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.repo.UpdateSuspended(ctx, user.ID, suspended); err != nil {
+		return nil, err
+	}
+	user.IsSuspended = suspended
+
+	if suspended {
+		if _, err := a.sessionStore.DeleteByUserID(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	a.log.InfoContext(ctx, "user suspension changed by operator", "op", "SetSuspended", "user_id", user.ID, "suspended", suspended)
+	return user, nil
+}
+
+func (a *adminService) AssignRole(ctx context.Context, email, roleName string) (*entity.UserEntity, error) {
+	user, err := a.repo.FindUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.repo.UpdateRole(ctx, user.ID, roleName); err != nil {
+		return nil, err
+	}
+	user.RoleName = roleName
+
+	if _, err := a.sessionStore.DeleteByUserID(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	a.log.InfoContext(ctx, "role assigned by operator", "op", "AssignRole", "user_id", user.ID, "role", roleName)
+	return user, nil
+}
+
+func (a *adminService) RevokeSession(ctx context.Context, token string) error {
+	if _, err := a.sessionStore.Get(ctx, token); err != nil {
+		return err
+	}
+	return a.sessionStore.Delete(ctx, token)
+}
+
+func (a *adminService) RevokeUserSessions(ctx context.Context, email string) (int, error) {
+	user, err := a.repo.FindUserByEmail(ctx, email)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked, err := a.sessionStore.DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	a.log.InfoContext(ctx, "sessions revoked by operator", "op", "RevokeUserSessions", "user_id", user.ID, "count", revoked)
+	return revoked, nil
+}
+
+func (a *adminService) MintToken(ctx context.Context, email string) (string, error) {
+	if a.cfg.App.AppEnv == config.EnvProduction {
+		return "", ErrMintInProduction
+	}
+
+	user, err := a.repo.FindUserByEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+	if user.IsSuspended {
+		return "", errs.ErrAccountSuspended
+	}
+
+	token, err := a.jwtService.GenerateToken(user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.sessionStore.Create(ctx, entity.SessionEntity{
+		Token:     token,
+		UserID:    user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		RoleName:  user.RoleName,
+		LoggedIn:  true,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+	a.log.WarnContext(ctx, "access token minted by operator", "op", "MintToken", "user_id", user.ID)
+	return token, nil
+}
+
+func NewAdminService(log *slog.Logger, cfg *config.Config, repo repository.UserRepositoryInterface, jwtService JwtServiceInterface, sessionStore port.SessionStoreInterface, passwords PasswordServiceInterface) AdminServiceInterface {
+	return &adminService{
+		log:          log,
+		cfg:          cfg,
+		repo:         repo,
+		jwtService:   jwtService,
+		sessionStore: sessionStore,
+		passwords:    passwords,
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+	"user-service/config"
+	"user-service/internal/adapter/message"
+	"user-service/internal/adapter/repository"
+	"user-service/internal/adapter/session"
+	"user-service/internal/adapter/throttle"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/errs"
+)
+
+// fakeUserRepo keeps users in memory. Methods the tests do not need are left
+// to the embedded nil interface and panic when called.
+type fakeUserRepo struct {
+	repository.UserRepositoryInterface
+
+	mu     sync.Mutex
+	nextID int64
+	users  map[int64]*entity.UserEntity
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{users: map[int64]*entity.UserEntity{}}
+}
+
+// add stores user and returns it with its new ID.
+func (f *fakeUserRepo) add(user entity.UserEntity) entity.UserEntity {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	user.ID = f.nextID
+	if user.RoleName == "" {
+		user.RoleName = entity.RoleCustomer
+	}
+	f.users[user.ID] = &user
+	return user
+}
+
+func (f *fakeUserRepo) get(userID int64) entity.UserEntity {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.users[userID]
+}
+
+func (f *fakeUserRepo) GetUserByEmail(ctx context.Context, email string) (*entity.UserEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, user := range f.users {
+		if user.Email == email {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, errs.ErrUserNotFound
+}
+
+func (f *fakeUserRepo) GetUserByID(ctx context.Context, userID int64) (*entity.UserEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user, ok := f.users[userID]
+	if !ok {
+		return nil, errs.ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (f *fakeUserRepo) CreateUserAccount(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, error) {
+	if _, err := f.GetUserByEmail(ctx, req.Email); err == nil {
+		return nil, errs.ErrEmailTaken
+	}
+	req.IsVerified = false
+	user := f.add(req)
+	return &user, nil
+}
+
+func (f *fakeUserRepo) UpdatePasswordByID(ctx context.Context, req entity.UserEntity) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user, ok := f.users[req.ID]
+	if !ok {
+		return errs.ErrUserNotFound
+	}
+	user.Password = req.Password
+	return nil
+}
+
+func (f *fakeUserRepo) RehashPassword(ctx context.Context, userID int64, oldHash, newHash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if user, ok := f.users[userID]; ok && user.Password == oldHash {
+		user.Password = newHash
+	}
+	return nil
+}
+
+// fakeTokenRepo keeps verification tokens in memory, in the clear.
+type fakeTokenRepo struct {
+	repository.VerificationTokenRepositoryInterface
+
+	mu       sync.Mutex
+	tokens   []entity.VerificationTokenEntity
+	consumed map[string]bool
+}
+
+func newFakeTokenRepo() *fakeTokenRepo {
+	return &fakeTokenRepo{consumed: map[string]bool{}}
+}
+
+// issued returns the tokens of tokenType created for userID, oldest first.
+func (f *fakeTokenRepo) issued(userID int64, tokenType string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var tokens []string
+	for _, token := range f.tokens {
+		if token.UserID == userID && token.TokenType == tokenType {
+			tokens = append(tokens, token.Token)
+		}
+	}
+	return tokens
+}
+
+func (f *fakeTokenRepo) CreateVerificationToken(ctx context.Context, req entity.VerificationTokenEntity) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.tokens = append(f.tokens, req)
+	return nil
+}
+
+func (f *fakeTokenRepo) GetToken(ctx context.Context, token, tokenType string) (*entity.VerificationTokenEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, stored := range f.tokens {
+		if stored.Token != token || stored.TokenType != tokenType || f.consumed[token] {
+			continue
+		}
+		if time.Now().After(stored.ExpiresAt) {
+			return nil, errs.ErrTokenExpired
+		}
+		return &stored, nil
+	}
+	return nil, errs.ErrTokenInvalid
+}
+
+func (f *fakeTokenRepo) ConsumeToken(ctx context.Context, token, tokenType string) (*entity.VerificationTokenEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, stored := range f.tokens {
+		if stored.Token != token || stored.TokenType != tokenType || f.consumed[token] {
+			continue
+		}
+		if time.Now().After(stored.ExpiresAt) {
+			return nil, errs.ErrTokenExpired
+		}
+		f.consumed[token] = true
+		return &stored, nil
+	}
+	return nil, errs.ErrTokenInvalid
+}
+
+// fakeUnitOfWork runs fn without a transaction; the fakes have nothing to
+// roll back.
+type fakeUnitOfWork struct{}
+
+func (fakeUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func testConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.App.JwtSecretKey = "test-secret"
+	cfg.App.JwtIssuer = "user-service-test"
+	cfg.App.DefaultLanguage = "en"
+	cfg.Token = config.Token{
+		EmailVerificationTTL: 24 * time.Hour,
+		ResetPasswordTTL:     time.Hour,
+		ResendCooldown:       time.Minute,
+	}
+	cfg.Links.VerifyEmail = config.ClientLinks{
+		Web:     "https://sayur.id/verify?token={token}&lang={lang}",
+		Android: "sayur://verify/{token}",
+	}
+	cfg.Links.ResetPassword = config.ClientLinks{
+		Web: "https://sayur.id/reset?token={token}",
+	}
+	cfg.Password = config.Password{
+		MinLength:         8,
+		MaxLength:         128,
+		RejectPersonal:    true,
+		Argon2Memory:      64,
+		Argon2Time:        1,
+		Argon2Parallelism: 1,
+	}
+	return cfg
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// userServiceFixture is a user service wired to in-memory dependencies.
+type userServiceFixture struct {
+	cfg       *config.Config
+	service   *userService
+	repo      *fakeUserRepo
+	tokens    *fakeTokenRepo
+	publisher *message.MemoryPublisher
+	passwords PasswordServiceInterface
+}
+
+func newUserServiceFixture(t *testing.T) *userServiceFixture {
+	t.Helper()
+
+	cfg := testConfig()
+	log := testLogger()
+	passwords, err := NewPasswordService(log, cfg, nil)
+	if err != nil {
+		t.Fatalf("NewPasswordService: %v", err)
+	}
+
+	f := &userServiceFixture{
+		cfg:       cfg,
+		repo:      newFakeUserRepo(),
+		tokens:    newFakeTokenRepo(),
+		publisher: message.NewMemoryPublisher(),
+		passwords: passwords,
+	}
+	f.service = NewUserService(log, f.repo, cfg, NewJwtService(cfg), f.tokens, f.publisher,
+		session.NewMemorySessionStore(time.Hour), fakeUnitOfWork{}, passwords, throttle.NewMemoryThrottle())
+	return f
+}
+
+// addUser stores a user whose password is hashed with the service's hasher.
+func (f *userServiceFixture) addUser(t *testing.T, user entity.UserEntity, plain string) entity.UserEntity {
+	t.Helper()
+
+	hash, err := f.passwords.Hash(plain)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	user.Password = hash
+	return f.repo.add(user)
+}
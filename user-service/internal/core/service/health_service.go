@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+	"user-service/internal/core/domain/entity"
+)
+
+var errShuttingDown = errors.New("service is shutting down")
+
+// HealthCheck is a named dependency probe, e.g. a database ping.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthServiceInterface interface {
+	Liveness(ctx context.Context) entity.HealthEntity
+	Readiness(ctx context.Context) entity.HealthEntity
+	SetShuttingDown()
+}
+
+type healthService struct {
+	checks   []HealthCheck
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	shuttingDown atomic.Bool
+
+	mu    sync.Mutex
+	cache map[string]entity.HealthCheckEntity
+}
+
+// Liveness implements HealthServiceInterface. It only tells whether the
+// process can serve requests and never touches dependencies, so a slow
+// database does not get the pod restarted.
+func (h *healthService) Liveness(ctx context.Context) entity.HealthEntity {
+	return entity.HealthEntity{Status: entity.HealthStatusUp}
+}
+
+// Readiness implements HealthServiceInterface. Checks run concurrently, each
+// with its own timeout, and results are reused for cacheTTL so frequent
+// probes do not hammer the dependencies.
+func (h *healthService) Readiness(ctx context.Context) entity.HealthEntity {
+	result := entity.HealthEntity{
+		Status: entity.HealthStatusUp,
+		Checks: make([]entity.HealthCheckEntity, len(h.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			result.Checks[i] = h.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	if h.shuttingDown.Load() {
+		result.Status = entity.HealthStatusDown
+		result.Checks = append(result.Checks, entity.HealthCheckEntity{
+			Name:      "shutdown",
+			Status:    entity.HealthStatusDown,
+			Error:     errShuttingDown.Error(),
+			CheckedAt: time.Now(),
+		})
+	}
+
+	for _, check := range result.Checks {
+		if check.Status != entity.HealthStatusUp {
+			result.Status = entity.HealthStatusDown
+		}
+	}
+	return result
+}
+
+// SetShuttingDown implements HealthServiceInterface. From now on readiness
+// fails so load balancers stop routing new traffic here.
+func (h *healthService) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+func (h *healthService) run(ctx context.Context, check HealthCheck) entity.HealthCheckEntity {
+	h.mu.Lock()
+	cached, ok := h.cache[check.Name]
+	h.mu.Unlock()
+	if ok && time.Since(cached.CheckedAt) < h.cacheTTL {
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	checked := entity.HealthCheckEntity{
+		Name:      check.Name,
+		Status:    entity.HealthStatusUp,
+		Duration:  time.Since(start),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		checked.Status = entity.HealthStatusDown
+		checked.Error = err.Error()
+	}
+
+	h.mu.Lock()
+	h.cache[check.Name] = checked
+	h.mu.Unlock()
+
+	return checked
+}
+
+func NewHealthService(timeout, cacheTTL time.Duration, checks ...HealthCheck) HealthServiceInterface {
+	return &healthService{
+		checks:   checks,
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]entity.HealthCheckEntity),
+	}
+}
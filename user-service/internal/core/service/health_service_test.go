@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"user-service/internal/core/domain/entity"
+)
+
+func TestHealthReadinessReportsFailingCheck(t *testing.T) {
+	health := NewHealthService(time.Second, 0,
+		HealthCheck{Name: "postgres", Check: func(ctx context.Context) error { return nil }},
+		HealthCheck{Name: "redis", Check: func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.3:6379: refused") }},
+	)
+
+	result := health.Readiness(context.Background())
+	if result.Status != entity.HealthStatusDown {
+		t.Fatalf("readiness = %s, want down", result.Status)
+	}
+	if result.Checks[0].Status != entity.HealthStatusUp || result.Checks[1].Status != entity.HealthStatusDown {
+		t.Errorf("checks = %+v, want postgres up and redis down", result.Checks)
+	}
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"errors"
+	"sync"
 	"time"
 	"user-service/config"
 
@@ -10,11 +12,15 @@ import (
 type JwtServiceInterface interface {
 	GenerateToken(userID int64) (string, error)
 	ValidateToken(token string) (*jwt.Token, error)
+	RotateSecretKey(secretKey string)
 }
 
 type jwtService struct {
+	mu        sync.RWMutex
 	secretKey string
-	issuer    string
+	// previousKey still validates tokens signed before the last rotation.
+	previousKey string
+	issuer      string
 }
 
 func (j *jwtService) GenerateToken(userID int64) (string, error) {
@@ -24,17 +30,46 @@ func (j *jwtService) GenerateToken(userID int64) (string, error) {
 		"exp":     time.Now().Add(time.Hour * 24).Unix(), // Token expires in 72 hours
 	}
 
+	j.mu.RLock()
+	secretKey := j.secretKey
+	j.mu.RUnlock()
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	return token.SignedString([]byte(secretKey))
 }
 
 func (j *jwtService) ValidateToken(encodeToken string) (*jwt.Token, error) {
+	j.mu.RLock()
+	secretKey, previousKey := j.secretKey, j.previousKey
+	j.mu.RUnlock()
+
+	token, err := j.parse(encodeToken, secretKey)
+	if err != nil && previousKey != "" && errors.Is(err, jwt.ErrSignatureInvalid) {
+		return j.parse(encodeToken, previousKey)
+	}
+	return token, err
+}
+
+// RotateSecretKey switches signing to secretKey while tokens signed with the
+// current key keep validating until the next rotation.
+func (j *jwtService) RotateSecretKey(secretKey string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if secretKey == j.secretKey {
+		return
+	}
+	j.previousKey = j.secretKey
+	j.secretKey = secretKey
+}
+
+func (j *jwtService) parse(encodeToken, secretKey string) (*jwt.Token, error) {
 	return jwt.Parse(encodeToken, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
 		}
 
-		return []byte(j.secretKey), nil
+		return []byte(secretKey), nil
 	})
 }
 
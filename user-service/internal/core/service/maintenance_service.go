@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"user-service/config"
+	"user-service/internal/adapter/repository"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/port"
+	"user-service/utils/i18n"
+	"user-service/utils/link"
+
+	"github.com/google/uuid"
+)
+
+// remindBatchSize bounds how many unverified users are loaded at once.
+const remindBatchSize = 100
+
+// MaintenanceServiceInterface holds the housekeeping run by the background
+// scheduler.
+type MaintenanceServiceInterface interface {
+	// PurgeStaleTokens deletes verification tokens that expired or were used
+	// longer than the token retention ago.
+	PurgeStaleTokens(ctx context.Context) error
+	PurgeExpiredSessions(ctx context.Context) error
+	// RemindUnverifiedUsers mails a fresh verification link, once, to users
+	// still unverified after the configured number of days. A reminder that
+	// could not be sent is retried after the configured retry interval.
+	RemindUnverifiedUsers(ctx context.Context) error
+	// DeleteUnverifiedUsers removes accounts still unverified after the
+	// configured number of days, except suspended ones and those an operator
+	// created.
+	DeleteUnverifiedUsers(ctx context.Context) error
+}
+
+type maintenanceService struct {
+	log          *slog.Logger
+	cfg          *config.Config
+	repo         repository.UserRepositoryInterface
+	repoToken    repository.VerificationTokenRepositoryInterface
+	sessionStore port.SessionStoreInterface
+	publisher    port.PublisherInterface
+	unitOfWork   port.UnitOfWorkInterface
+	links        *link.Builder
+}
+
+func (m *maintenanceService) PurgeStaleTokens(ctx context.Context) error {
+	deleted, err := m.repoToken.DeleteStaleTokens(ctx, time.Now().Add(-m.cfg.Scheduler.TokenRetention))
+	if err != nil {
+		return err
+	}
+	m.log.InfoContext(ctx, "stale verification tokens purged", "op", "PurgeStaleTokens", "count", deleted)
+	return nil
+}
+
+func (m *maintenanceService) PurgeExpiredSessions(ctx context.Context) error {
+	deleted, err := m.sessionStore.DeleteExpired(ctx)
+	if err != nil {
+		return err
+	}
+	m.log.InfoContext(ctx, "expired sessions purged", "op", "PurgeExpiredSessions", "count", deleted)
+	return nil
+}
+
+func (m *maintenanceService) RemindUnverifiedUsers(ctx context.Context) error {
+	createdBefore := time.Now().AddDate(0, 0, -m.cfg.Scheduler.UnverifiedRemindDays)
+	// users whose reminder failed drop out of the batches until the retry
+	// interval passed, so this run does not load them again either
+	attemptedBefore := time.Now().Add(-m.cfg.Scheduler.UnverifiedRemindRetry)
+
+	reminded, failed := 0, 0
+	for {
+		users, err := m.repo.GetUnverifiedUsersToRemind(ctx, createdBefore, attemptedBefore, remindBatchSize)
+		if err != nil {
+			return err
+		}
+
+		sent := 0
+		for _, user := range users {
+			if err := m.remind(ctx, user); err != nil {
+				m.log.ErrorContext(ctx, "failed to remind unverified user", "op", "RemindUnverifiedUsers", "user_id", user.ID, "error", err)
+				if err := m.repo.MarkVerificationRemindAttempted(ctx, user.ID); err != nil {
+					return err
+				}
+				failed++
+				continue
+			}
+			sent++
+		}
+		reminded += sent
+
+		// a batch that failed entirely would be loaded again forever
+		if len(users) < remindBatchSize || sent == 0 {
+			break
+		}
+	}
+
+	m.log.InfoContext(ctx, "unverified users reminded", "op", "RemindUnverifiedUsers", "count", reminded, "failed", failed)
+	return nil
+}
+
+// remind publishes inside the unit of work and stamps the user reminded
+// last, so a reminder that could not be handed to the broker leaves neither
+// the token nor the stamp behind.
+func (m *maintenanceService) remind(ctx context.Context, user entity.UserEntity) error {
+	return m.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		token := uuid.New().String()
+		err := m.repoToken.CreateVerificationToken(ctx, entity.VerificationTokenEntity{
+			UserID:    user.ID,
+			Token:     token,
+			TokenType: entity.TokenTypeEmailVerification,
+			ExpiresAt: time.Now().Add(m.cfg.Token.EmailVerificationTTL),
+		})
+		if err != nil {
+			return err
+		}
+
+		// the client the user signed up with is not kept, so reminders link to the web
+		lang := i18n.Normalize(user.Language, m.cfg.App.DefaultLanguage)
+		urlVerify, err := m.links.Build(link.VerifyEmail, link.Web, token, lang)
+		if err != nil {
+			return err
+		}
+		err = m.publisher.Publish(ctx, entity.NotificationEntity{
+			Email:            user.Email,
+			Message:          i18n.T(lang, "notification.verification_reminder", urlVerify),
+			NotificationType: "user_verification",
+		})
+		if err != nil {
+			return err
+		}
+		return m.repo.MarkVerificationReminded(ctx, user.ID)
+	})
+}
+
+func (m *maintenanceService) DeleteUnverifiedUsers(ctx context.Context) error {
+	createdBefore := time.Now().AddDate(0, 0, -m.cfg.Scheduler.UnverifiedDeleteDays)
+
+	deleted, err := m.repo.DeleteUnverifiedUsers(ctx, createdBefore)
+	if err != nil {
+		return err
+	}
+	m.log.InfoContext(ctx, "unverified users deleted", "op", "DeleteUnverifiedUsers", "count", deleted)
+	return nil
+}
+
+func NewMaintenanceService(log *slog.Logger, cfg *config.Config, repo repository.UserRepositoryInterface, repoToken repository.VerificationTokenRepositoryInterface, sessionStore port.SessionStoreInterface, publisher port.PublisherInterface, unitOfWork port.UnitOfWorkInterface) MaintenanceServiceInterface {
+	return &maintenanceService{
+		log:          log,
+		cfg:          cfg,
+		repo:         repo,
+		repoToken:    repoToken,
+		sessionStore: sessionStore,
+		publisher:    publisher,
+		unitOfWork:   unitOfWork,
+		links:        cfg.NewLinkBuilder(),
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"user-service/internal/adapter/message"
+	"user-service/internal/adapter/session"
+	"user-service/internal/core/domain/entity"
+)
+
+// remindRepo tracks the reminder stamps of the users in fakeUserRepo.
+type remindRepo struct {
+	*fakeUserRepo
+
+	reminded  map[int64]time.Time
+	attempted map[int64]time.Time
+}
+
+func (r *remindRepo) GetUnverifiedUsersToRemind(ctx context.Context, createdBefore, attemptedBefore time.Time, limit int) ([]entity.UserEntity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := []entity.UserEntity{}
+	for id, user := range r.users {
+		if user.IsVerified {
+			continue
+		}
+		if _, ok := r.reminded[id]; ok {
+			continue
+		}
+		if at, ok := r.attempted[id]; ok && !at.Before(attemptedBefore) {
+			continue
+		}
+		users = append(users, *user)
+	}
+	return users, nil
+}
+
+func (r *remindRepo) MarkVerificationReminded(ctx context.Context, userID int64) error {
+	r.reminded[userID] = time.Now()
+	return nil
+}
+
+func (r *remindRepo) MarkVerificationRemindAttempted(ctx context.Context, userID int64) error {
+	r.attempted[userID] = time.Now()
+	return nil
+}
+
+// flakyPublisher fails every publish while down is set.
+type flakyPublisher struct {
+	*message.MemoryPublisher
+	down bool
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, notification entity.NotificationEntity) error {
+	if p.down {
+		return errors.New("broker unreachable")
+	}
+	return p.MemoryPublisher.Publish(ctx, notification)
+}
+
+func TestRemindUnverifiedUsersBacksOffAfterAFailedSend(t *testing.T) {
+	cfg := testConfig()
+	cfg.Scheduler.UnverifiedRemindDays = 3
+	cfg.Scheduler.UnverifiedRemindRetry = time.Hour
+
+	repo := &remindRepo{fakeUserRepo: newFakeUserRepo(), reminded: map[int64]time.Time{}, attempted: map[int64]time.Time{}}
+	user := repo.add(entity.UserEntity{Name: "Budi Santoso", Email: "budi@example.com"})
+	publisher := &flakyPublisher{MemoryPublisher: message.NewMemoryPublisher(), down: true}
+	maintenance := NewMaintenanceService(testLogger(), cfg, repo, newFakeTokenRepo(), session.NewMemorySessionStore(time.Hour), publisher, fakeUnitOfWork{})
+
+	if err := maintenance.RemindUnverifiedUsers(context.Background()); err != nil {
+		t.Fatalf("RemindUnverifiedUsers: %v", err)
+	}
+	if _, ok := repo.reminded[user.ID]; ok {
+		t.Fatal("user stamped reminded although the reminder was not sent")
+	}
+	if _, ok := repo.attempted[user.ID]; !ok {
+		t.Fatal("failed attempt not recorded")
+	}
+
+	// the broker is back, but the retry interval has not passed yet
+	publisher.down = false
+	if err := maintenance.RemindUnverifiedUsers(context.Background()); err != nil {
+		t.Fatalf("RemindUnverifiedUsers: %v", err)
+	}
+	if n := len(publisher.Messages()); n != 0 {
+		t.Fatalf("got %d reminders within the retry interval, want none", n)
+	}
+
+	repo.attempted[user.ID] = time.Now().Add(-2 * time.Hour)
+	if err := maintenance.RemindUnverifiedUsers(context.Background()); err != nil {
+		t.Fatalf("RemindUnverifiedUsers: %v", err)
+	}
+	if n := len(publisher.Messages()); n != 1 {
+		t.Fatalf("got %d reminders after the retry interval, want 1", n)
+	}
+	if _, ok := repo.reminded[user.ID]; !ok {
+		t.Error("user not stamped reminded after the reminder was sent")
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"user-service/config"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/errs"
+	"user-service/internal/core/port"
+	"user-service/utils/i18n"
+	"user-service/utils/password"
+)
+
+// PasswordServiceInterface enforces the password policy on every password a
+// user or operator sets, and hashes passwords for storage.
+type PasswordServiceInterface interface {
+	// Validate returns a weak password error listing every rule password
+	// breaks. The name and email of owner, when set, must not appear in it.
+	Validate(ctx context.Context, password string, owner entity.UserEntity) error
+	// Hash hashes password with the current algorithm and parameters.
+	Hash(password string) (string, error)
+	// Verify reports whether password matches hash, and whether hash should
+	// be replaced with a fresh Hash because it uses an older algorithm or
+	// parameters.
+	Verify(password, hash string) (ok, rehash bool, err error)
+}
+
+type passwordService struct {
+	log    *slog.Logger
+	policy *password.Policy
+	breach port.BreachedPasswordProviderInterface
+	hasher *password.Hasher
+}
+
+func (p *passwordService) Hash(plain string) (string, error) {
+	return p.hasher.Hash(plain)
+}
+
+func (p *passwordService) Verify(plain, hash string) (bool, bool, error) {
+	return p.hasher.Verify(plain, hash)
+}
+
+func (p *passwordService) Validate(ctx context.Context, plain string, owner entity.UserEntity) error {
+	violations := p.policy.Check(plain, owner.Name, owner.Email)
+
+	// a password the policy already rejects is not worth a lookup
+	if len(violations) == 0 && p.breach != nil {
+		breached, err := p.breached(ctx, plain)
+		switch {
+		case err != nil:
+			// an unreachable provider must not stop users from setting passwords
+			p.log.WarnContext(ctx, "breached password check failed, skipped", "op", "Validate", "error", err)
+		case breached:
+			violations = append(violations, password.Violation{Rule: password.RuleBreached})
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	lang := i18n.Language(ctx)
+	fields := make([]errs.FieldError, 0, len(violations))
+	for _, violation := range violations {
+		var args []any
+		if violation.Param != "" {
+			args = append(args, violation.Param)
+		}
+		fields = append(fields, errs.FieldError{
+			Field:   "password",
+			Rule:    violation.Rule,
+			Param:   violation.Param,
+			Message: i18n.T(lang, "password."+violation.Rule, args...),
+		})
+	}
+	return errs.WeakPassword(fields...)
+}
+
+// breached reports whether plain is in a known breach. Only the prefix of
+// its hash is handed to the provider.
+func (p *passwordService) breached(ctx context.Context, plain string) (bool, error) {
+	prefix, suffix := password.RangeKey(plain)
+	suffixes, err := p.breach.Range(ctx, prefix)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range suffixes {
+		if s == suffix {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NewPasswordService loads the password policy from cfg. A nil breach skips
+// the breached password check.
+func NewPasswordService(log *slog.Logger, cfg *config.Config, breach port.BreachedPasswordProviderInterface) (PasswordServiceInterface, error) {
+	policy, err := password.NewPolicy(password.Policy{
+		MinLength:      cfg.Password.MinLength,
+		MaxLength:      cfg.Password.MaxLength,
+		RequireUpper:   cfg.Password.RequireUpper,
+		RequireLower:   cfg.Password.RequireLower,
+		RequireDigit:   cfg.Password.RequireDigit,
+		RequireSymbol:  cfg.Password.RequireSymbol,
+		RejectPersonal: cfg.Password.RejectPersonal,
+	}, cfg.Password.BlocklistFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &passwordService{
+		log:    log,
+		policy: policy,
+		breach: breach,
+		hasher: cfg.NewPasswordHasher(),
+	}, nil
+}
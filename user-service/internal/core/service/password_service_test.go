@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/errs"
+	"user-service/utils/password"
+)
+
+// fakeBreachProvider answers ranges from suffixes, or fails with err.
+type fakeBreachProvider struct {
+	suffixes []string
+	err      error
+	prefixes []string
+}
+
+func (f *fakeBreachProvider) Range(ctx context.Context, prefix string) ([]string, error) {
+	f.prefixes = append(f.prefixes, prefix)
+	return f.suffixes, f.err
+}
+
+func TestPasswordServiceValidate(t *testing.T) {
+	const plain = "kebun-tomat-segar"
+	prefix, suffix := password.RangeKey(plain)
+
+	tests := []struct {
+		name     string
+		provider *fakeBreachProvider
+		plain    string
+		wantRule string
+		wantAsk  bool
+	}{
+		{name: "not breached", provider: &fakeBreachProvider{suffixes: []string{"0018A45C4D1DEF81644B54AB7F969B88D65"}}, plain: plain, wantAsk: true},
+		{name: "breached", provider: &fakeBreachProvider{suffixes: []string{"0018A45C4D1DEF81644B54AB7F969B88D65", suffix}}, plain: plain, wantRule: password.RuleBreached, wantAsk: true},
+		{name: "provider unreachable", provider: &fakeBreachProvider{err: errors.New("dial tcp: i/o timeout")}, plain: plain, wantAsk: true},
+		{name: "policy failure skips the lookup", provider: &fakeBreachProvider{suffixes: []string{suffix}}, plain: "short", wantRule: password.RuleMinLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passwords, err := NewPasswordService(testLogger(), testConfig(), tt.provider)
+			if err != nil {
+				t.Fatalf("NewPasswordService: %v", err)
+			}
+
+			err = passwords.Validate(context.Background(), tt.plain, entity.UserEntity{Name: "Siti Rahma"})
+			if tt.wantRule == "" {
+				if err != nil {
+					t.Errorf("Validate = %v, want nil", err)
+				}
+			} else {
+				var weak *errs.Error
+				if !errors.As(err, &weak) || weak.Code != errs.CodeWeakPassword {
+					t.Fatalf("Validate = %v, want a weak password error", err)
+				}
+				if len(weak.Fields) != 1 || weak.Fields[0].Rule != tt.wantRule {
+					t.Errorf("fields = %+v, want only %s", weak.Fields, tt.wantRule)
+				}
+			}
+
+			asked := len(tt.provider.prefixes) > 0
+			if asked != tt.wantAsk {
+				t.Errorf("provider asked = %v, want %v", asked, tt.wantAsk)
+			}
+			if asked && (len(tt.provider.prefixes) != 1 || tt.provider.prefixes[0] != prefix) {
+				t.Errorf("provider asked for %v, want only the prefix %s", tt.provider.prefixes, prefix)
+			}
+		})
+	}
+}
@@ -2,94 +2,123 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 	"user-service/config"
-	"user-service/internal/adapter/message"
 	"user-service/internal/adapter/repository"
 	"user-service/internal/core/domain/entity"
-	"user-service/utils/conv"
+	"user-service/internal/core/domain/errs"
+	"user-service/internal/core/port"
+	"user-service/utils/i18n"
+	"user-service/utils/link"
 
 	"github.com/google/uuid"
-	"github.com/labstack/gommon/log"
 )
 
 type UserServiceInterface interface {
 	SignIn(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, string, error)
 	CreateUserAccount(ctx context.Context, req entity.UserEntity) error
 	ForgotPassword(ctx context.Context, req entity.UserEntity) error
+	ResendVerification(ctx context.Context, req entity.UserEntity) error
 	VerifyToken(ctx context.Context, token string) (*entity.UserEntity, error)
 	UpdatePassword(ctx context.Context, req entity.UserEntity) error
+	ValidateAccessToken(ctx context.Context, token string) (*entity.SessionEntity, error)
+	GetUserByID(ctx context.Context, userID int64) (*entity.UserEntity, error)
+	GetUsersByIDs(ctx context.Context, userIDs []int64) ([]entity.UserEntity, error)
+	CheckPermission(ctx context.Context, userID int64, permission string) (bool, string, error)
 }
 
 type userService struct {
-	repo       repository.UserRepositoryInterface
-	cfg        *config.Config
-	jwtService JwtServiceInterface
-	repoToken  repository.VerificationTokenRepositoryInterface
+	log          *slog.Logger
+	repo         repository.UserRepositoryInterface
+	cfg          *config.Config
+	jwtService   JwtServiceInterface
+	repoToken    repository.VerificationTokenRepositoryInterface
+	publisher    port.PublisherInterface
+	sessionStore port.SessionStoreInterface
+	unitOfWork   port.UnitOfWorkInterface
+	passwords    PasswordServiceInterface
+	throttle     port.ThrottleInterface
+	links        *link.Builder
 }
 
 func (u *userService) UpdatePassword(ctx context.Context, req entity.UserEntity) error {
-	token, err := u.repoToken.GetDataByToken(ctx, req.Token)
+	// the password is checked against its owner before the transaction, so
+	// the breached password lookup holds no lock; the token is consumed below
+	token, err := u.repoToken.GetToken(ctx, req.Token, entity.TokenTypeResetPassword)
 	if err != nil {
-		log.Errorf("[UserService-13] UpdatePassword: %v", err)
+		u.log.WarnContext(ctx, "invalid reset password token", "op", "UpdatePassword", "error", err)
 		return err
 	}
-	if token.TokenType != "reset_password" {
-		err = errors.New("401")
-		log.Errorf("[UserService-14] UpdatePassword: %v", err)
+	user, err := u.repo.GetUserByID(ctx, token.UserID)
+	if err != nil {
 		return err
 	}
-
-	password, err := conv.HashPassword(req.Password)
-	if err != nil {
-		log.Errorf("[UserService-15] UpdatePassword: %v", err)
+	if err := u.passwords.Validate(ctx, req.Password, *user); err != nil {
 		return err
 	}
-	req.Password = password
-	req.ID = token.UserID
 
-	err = u.repo.UpdatePasswordByID(ctx, req)
+	password, err := u.passwords.Hash(req.Password)
 	if err != nil {
-		log.Errorf("[UserService-16] UpdatePassword: %v", err)
+		u.log.ErrorContext(ctx, "failed to hash password", "op", "UpdatePassword", "error", err)
 		return err
 	}
+	req.Password = password
 
-	return nil
+	// the token stays usable when the password cannot be saved
+	return u.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		token, err := u.repoToken.ConsumeToken(ctx, req.Token, entity.TokenTypeResetPassword)
+		if err != nil {
+			u.log.WarnContext(ctx, "invalid reset password token", "op", "UpdatePassword", "error", err)
+			return err
+		}
+		req.ID = token.UserID
+
+		if err := u.repo.UpdatePasswordByID(ctx, req); err != nil {
+			u.log.ErrorContext(ctx, "failed to update password", "op", "UpdatePassword", "user_id", req.ID, "error", err)
+			return err
+		}
+		return nil
+	})
 }
+
 func (u *userService) VerifyToken(ctx context.Context, token string) (*entity.UserEntity, error) {
-	verifyToken, err := u.repoToken.GetDataByToken(ctx, token)
-	if err != nil {
-		log.Errorf("[UserService-11] VerifyToken: %v", err)
-		return nil, err
-	}
+	var user *entity.UserEntity
+	err := u.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		verifyToken, err := u.repoToken.ConsumeToken(ctx, token, entity.TokenTypeEmailVerification)
+		if err != nil {
+			u.log.WarnContext(ctx, "invalid verification token", "op", "VerifyToken", "error", err)
+			return err
+		}
 
-	user, err := u.repo.UpdateUserVerified(ctx, verifyToken.UserID)
+		user, err = u.repo.UpdateUserVerified(ctx, verifyToken.UserID)
+		if err != nil {
+			u.log.ErrorContext(ctx, "failed to verify user", "op", "VerifyToken", "user_id", verifyToken.UserID, "error", err)
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		log.Errorf("[UserService-12] VerifyToken: %v", err)
 		return nil, err
 	}
+	if user.IsSuspended {
+		u.log.WarnContext(ctx, "suspended user tried to sign in", "op", "VerifyToken", "user_id", user.ID)
+		return nil, errs.ErrAccountSuspended
+	}
 
 	accessToken, err := u.jwtService.GenerateToken(user.ID)
 	if err != nil {
-		log.Errorf("[UserService-3] SignIn: %v", err)
+		u.log.ErrorContext(ctx, "failed to generate access token", "op", "VerifyToken", "user_id", user.ID, "error", err)
 		return nil, err
 	}
 
-	sessionData := map[string]interface{}{
-		"user_id":    user.ID,
-		"name":       user.Name,
-		"email":      user.Email,
-		"logged_in":  true,
-		"created_at": time.Now().String(),
-		"token":      token,
-	}
-
-	redisConn := config.NewRedisClient()
-	err = redisConn.Set(ctx, token, sessionData, time.Hour*23).Err()
+	err = u.createSession(ctx, user, accessToken)
 	if err != nil {
-		log.Errorf("[UserService-4] SignIn: %v", err)
+		u.log.ErrorContext(ctx, "failed to create session", "op", "VerifyToken", "user_id", user.ID, "error", err)
 		return nil, err
 	}
 
@@ -99,30 +128,57 @@ func (u *userService) VerifyToken(ctx context.Context, token string) (*entity.Us
 }
 
 func (u *userService) ForgotPassword(ctx context.Context, req entity.UserEntity) error {
+	// throttled by email before the lookup, and unknown or unverified emails
+	// answered like registered ones, so the endpoint does not reveal accounts
+	allowed, err := u.allowEmail(ctx, "forgot_password", req.Email)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		u.log.InfoContext(ctx, "password reset throttled", "op", "ForgotPassword", "email", req.Email)
+		return errs.ErrTooManyRequests
+	}
+
 	user, err := u.repo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		log.Errorf("[UserService-8] ForgotPassword: %v", err)
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return nil
+		}
+		u.log.ErrorContext(ctx, "failed to find user", "op", "ForgotPassword", "email", req.Email, "error", err)
 		return err
 	}
+	if !user.IsVerified {
+		u.log.InfoContext(ctx, "password reset for unverified user ignored", "op", "ForgotPassword", "user_id", user.ID)
+		return nil
+	}
 
 	token := uuid.New().String()
 	reqEntity := entity.VerificationTokenEntity{
 		UserID:    user.ID,
 		Token:     token,
-		TokenType: "reset_password",
+		TokenType: entity.TokenTypeResetPassword,
+		ExpiresAt: time.Now().Add(u.cfg.Token.ResetPasswordTTL),
 	}
 
 	err = u.repoToken.CreateVerificationToken(ctx, reqEntity)
 	if err != nil {
-		log.Errorf("[UserService-9] ForgotPassword: %v", err)
+		u.log.ErrorContext(ctx, "failed to create reset password token", "op", "ForgotPassword", "user_id", user.ID, "error", err)
 		return err
 	}
 
-	urlForgot := fmt.Sprintf("%s/forgot-password?token=%s", u.cfg.App.UrlForgotPassword, token)
-	messageParam := fmt.Sprintf("Please click link below for reset password: %v", urlForgot)
-	err = message.PublishMessage(req.Email, messageParam, "reset_password")
+	lang := i18n.Normalize(user.Language, u.cfg.App.DefaultLanguage)
+	urlForgot, err := u.links.Build(link.ResetPassword, req.Client, token, lang)
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to build reset password link", "op", "ForgotPassword", "client", req.Client, "error", err)
+		return err
+	}
+	err = u.publisher.Publish(ctx, entity.NotificationEntity{
+		Email:            req.Email,
+		Message:          i18n.T(lang, "notification.reset_password", urlForgot),
+		NotificationType: "reset_password",
+	})
 	if err != nil {
-		log.Errorf("[UserService-10] ForgotPassword: %v", err)
+		u.log.ErrorContext(ctx, "failed to publish reset password notification", "op", "ForgotPassword", "user_id", user.ID, "error", err)
 		return err
 	}
 	return nil
@@ -130,76 +186,259 @@ func (u *userService) ForgotPassword(ctx context.Context, req entity.UserEntity)
 
 // CreateUserAccount implement UserServiceInterface
 func (u *userService) CreateUserAccount(ctx context.Context, req entity.UserEntity) error {
-	password, err := conv.HashPassword(req.Password)
+	if err := u.passwords.Validate(ctx, req.Password, req); err != nil {
+		return err
+	}
+
+	password, err := u.passwords.Hash(req.Password)
 	if err != nil {
-		log.Errorf("[UserService-5] CreateUserAccount: %v", err)
+		u.log.ErrorContext(ctx, "failed to hash password", "op", "CreateUserAccount", "error", err)
 		return err
 	}
 
 	req.Password = password
-	token := uuid.New().String()
-	req.Token = token
+	// without an explicit choice the account keeps the language it signed up in
+	if req.Language == "" {
+		req.Language = i18n.Language(ctx)
+	}
+	req.Token = uuid.New().String()
 
-	err = u.repo.CreateUserAccount(ctx, req)
+	// a user without a token could never verify, so both are created or neither
+	err = u.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		user, err := u.repo.CreateUserAccount(ctx, req)
+		if err != nil {
+			return err
+		}
+		return u.repoToken.CreateVerificationToken(ctx, entity.VerificationTokenEntity{
+			UserID:    user.ID,
+			Token:     req.Token,
+			TokenType: entity.TokenTypeEmailVerification,
+			ExpiresAt: time.Now().Add(u.cfg.Token.EmailVerificationTTL),
+		})
+	})
 	if err != nil {
-		log.Errorf("[UserService-6] CreateUserAccount: %v", err)
+		u.log.ErrorContext(ctx, "failed to create user", "op", "CreateUserAccount", "email", req.Email, "error", err)
 		return err
 	}
 
-	urlVerify := fmt.Sprintf("http://localhost:8080/verify?token=%v", req.Token)
-	messageparam := fmt.Sprintf("Please verify your account with click link below: %s", urlVerify)
-	err = message.PublishMessage(req.Email, messageparam, "user_verification")
+	err = u.sendVerification(ctx, req.Email, req.Language, req.Client, req.Token)
 	if err != nil {
-		log.Errorf("[UserService-7] CreateUserAccount: %v", err)
+		u.log.ErrorContext(ctx, "failed to publish verification notification", "op", "CreateUserAccount", "email", req.Email, "error", err)
 		return err
 	}
 
 	return nil
 }
 
+// ResendVerification implement UserServiceInterface. Unknown and already
+// verified emails are ignored without an error, so the endpoint does not
+// reveal which emails are registered. Each email, registered or not, may ask
+// at most once per resend cooldown.
+func (u *userService) ResendVerification(ctx context.Context, req entity.UserEntity) error {
+	// throttled by email before the lookup, so unknown, verified and pending
+	// accounts get the same answers
+	allowed, err := u.allowEmail(ctx, "resend_verification", req.Email)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		u.log.InfoContext(ctx, "verification resend throttled", "op", "ResendVerification", "email", req.Email)
+		return errs.ErrTooManyRequests
+	}
+
+	user, err := u.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, errs.ErrUserNotFound) {
+			return nil
+		}
+		u.log.ErrorContext(ctx, "failed to find user", "op", "ResendVerification", "email", req.Email, "error", err)
+		return err
+	}
+	if user.IsVerified {
+		u.log.InfoContext(ctx, "verification resend for verified user ignored", "op", "ResendVerification", "user_id", user.ID)
+		return nil
+	}
+
+	// older tokens of the user stop working once this one is stored
+	token := uuid.New().String()
+	err = u.repoToken.CreateVerificationToken(ctx, entity.VerificationTokenEntity{
+		UserID:    user.ID,
+		Token:     token,
+		TokenType: entity.TokenTypeEmailVerification,
+		ExpiresAt: time.Now().Add(u.cfg.Token.EmailVerificationTTL),
+	})
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to create verification token", "op", "ResendVerification", "user_id", user.ID, "error", err)
+		return err
+	}
+
+	lang := i18n.Normalize(user.Language, u.cfg.App.DefaultLanguage)
+	if err := u.sendVerification(ctx, user.Email, lang, req.Client, token); err != nil {
+		u.log.ErrorContext(ctx, "failed to publish verification notification", "op", "ResendVerification", "user_id", user.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// allowEmail reports whether another email of kind may go to email within
+// the resend cooldown. The throttle is keyed by the normalized email, hashed
+// so the store holds no addresses.
+func (u *userService) allowEmail(ctx context.Context, kind, email string) (bool, error) {
+	if u.cfg.Token.ResendCooldown <= 0 {
+		return true, nil
+	}
+
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return u.throttle.Allow(ctx, kind+":"+hex.EncodeToString(sum[:]), u.cfg.Token.ResendCooldown)
+}
+
+// sendVerification mails the verification link for token, built for
+// client.
+func (u *userService) sendVerification(ctx context.Context, email, lang, client, token string) error {
+	urlVerify, err := u.links.Build(link.VerifyEmail, client, token, lang)
+	if err != nil {
+		return err
+	}
+	return u.publisher.Publish(ctx, entity.NotificationEntity{
+		Email:            email,
+		Message:          i18n.T(lang, "notification.user_verification", urlVerify),
+		NotificationType: "user_verification",
+	})
+}
+
 func (u *userService) SignIn(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, string, error) {
 	user, err := u.repo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		log.Errorf("[UserService-1] SignIn: %v", err)
+		u.log.WarnContext(ctx, "failed to find user", "op", "SignIn", "email", req.Email, "error", err)
 		return nil, "", err
 	}
 
-	if checkPass := conv.CheckPasswordHash(req.Password, user.Password); !checkPass {
-		err = errors.New("password is incorrect")
-		log.Errorf("[UserService-2] SignIn: %v", err)
-		return nil, "", err
+	ok, rehash, err := u.passwords.Verify(req.Password, user.Password)
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to verify password", "op", "SignIn", "user_id", user.ID, "error", err)
+		return nil, "", errs.ErrInvalidCredentials
+	}
+	if !ok {
+		u.log.WarnContext(ctx, "incorrect password", "op", "SignIn", "user_id", user.ID)
+		return nil, "", errs.ErrInvalidCredentials
+	}
+	// only after the password check, so the state of an account is not
+	// revealed to whoever guesses its email
+	if !user.IsVerified {
+		u.log.InfoContext(ctx, "unverified user tried to sign in", "op", "SignIn", "user_id", user.ID)
+		return nil, "", errs.ErrAccountUnverified
+	}
+	if user.IsSuspended {
+		u.log.WarnContext(ctx, "suspended user tried to sign in", "op", "SignIn", "user_id", user.ID)
+		return nil, "", errs.ErrAccountSuspended
 	}
 
 	token, err := u.jwtService.GenerateToken(user.ID)
 	if err != nil {
-		log.Errorf("[UserService-3] SignIn: %v", err)
+		u.log.ErrorContext(ctx, "failed to generate access token", "op", "SignIn", "user_id", user.ID, "error", err)
 		return nil, "", err
 	}
 
-	sessionData := map[string]interface{}{
-		"user_id":    user.ID,
-		"name":       user.Name,
-		"email":      user.Email,
-		"logged_in":  true,
-		"created_at": time.Now().String(),
-		"token":      token,
-	}
-
-	redisConn := config.NewRedisClient()
-	err = redisConn.Set(ctx, token, sessionData, time.Hour*23).Err()
+	err = u.createSession(ctx, user, token)
 	if err != nil {
-		log.Errorf("[UserService-4] SignIn: %v", err)
+		u.log.ErrorContext(ctx, "failed to create session", "op", "SignIn", "user_id", user.ID, "error", err)
 		return nil, "", err
 	}
 
+	if rehash {
+		u.rehashPassword(ctx, user, req.Password)
+	}
+
 	return user, token, nil
 }
 
-func NewUserService(repo repository.UserRepositoryInterface, cfg *config.Config, jwtService JwtServiceInterface, repoToken repository.VerificationTokenRepositoryInterface) *userService {
+// rehashPassword moves the password of a user that just signed in to the
+// current hash algorithm and parameters. Failing only costs another try at
+// the next sign in, so the sign in goes on.
+func (u *userService) rehashPassword(ctx context.Context, user *entity.UserEntity, plain string) {
+	hash, err := u.passwords.Hash(plain)
+	if err != nil {
+		u.log.WarnContext(ctx, "failed to rehash password", "op", "SignIn", "user_id", user.ID, "error", err)
+		return
+	}
+	if err := u.repo.RehashPassword(ctx, user.ID, user.Password, hash); err != nil {
+		u.log.WarnContext(ctx, "failed to save rehashed password", "op", "SignIn", "user_id", user.ID, "error", err)
+		return
+	}
+	u.log.InfoContext(ctx, "password rehashed", "op", "SignIn", "user_id", user.ID)
+}
+
+// ValidateAccessToken returns the session of a signed, unexpired access
+// token. Tokens failing the signature check or without a live session are
+// reported as errs.ErrTokenInvalid and errs.ErrSessionNotFound.
+func (u *userService) ValidateAccessToken(ctx context.Context, token string) (*entity.SessionEntity, error) {
+	if _, err := u.jwtService.ValidateToken(token); err != nil {
+		u.log.InfoContext(ctx, "access token rejected", "op", "ValidateAccessToken", "error", err)
+		return nil, errs.ErrTokenInvalid.WithCause(err)
+	}
+
+	session, err := u.sessionStore.Get(ctx, token)
+	if err != nil {
+		if !errors.Is(err, port.ErrSessionNotFound) {
+			u.log.ErrorContext(ctx, "failed to get session", "op", "ValidateAccessToken", "error", err)
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+func (u *userService) GetUserByID(ctx context.Context, userID int64) (*entity.UserEntity, error) {
+	return u.repo.GetUserByID(ctx, userID)
+}
+
+func (u *userService) GetUsersByIDs(ctx context.Context, userIDs []int64) ([]entity.UserEntity, error) {
+	if len(userIDs) == 0 {
+		return []entity.UserEntity{}, nil
+	}
+	return u.repo.GetUsersByIDs(ctx, userIDs)
+}
+
+// CheckPermission reports whether the role of the user grants permission,
+// along with that role.
+func (u *userService) CheckPermission(ctx context.Context, userID int64, permission string) (bool, string, error) {
+	user, err := u.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, "", err
+	}
+
+	for _, granted := range entity.RolePermissions[user.RoleName] {
+		if granted == entity.PermissionAll || granted == permission {
+			return true, user.RoleName, nil
+		}
+	}
+	return false, user.RoleName, nil
+}
+
+// createSession stores the session for a freshly issued access token.
+func (u *userService) createSession(ctx context.Context, user *entity.UserEntity, accessToken string) error {
+	return u.sessionStore.Create(ctx, entity.SessionEntity{
+		Token:     accessToken,
+		UserID:    user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		RoleName:  user.RoleName,
+		LoggedIn:  true,
+		CreatedAt: time.Now(),
+	})
+}
+
+func NewUserService(log *slog.Logger, repo repository.UserRepositoryInterface, cfg *config.Config, jwtService JwtServiceInterface, repoToken repository.VerificationTokenRepositoryInterface, publisher port.PublisherInterface, sessionStore port.SessionStoreInterface, unitOfWork port.UnitOfWorkInterface, passwords PasswordServiceInterface, throttle port.ThrottleInterface) *userService {
 	return &userService{
-		repo:       repo,
-		cfg:        cfg,
-		jwtService: jwtService,
-		repoToken:  repoToken,
+		log:          log,
+		repo:         repo,
+		cfg:          cfg,
+		jwtService:   jwtService,
+		repoToken:    repoToken,
+		publisher:    publisher,
+		sessionStore: sessionStore,
+		unitOfWork:   unitOfWork,
+		passwords:    passwords,
+		throttle:     throttle,
+		links:        cfg.NewLinkBuilder(),
 	}
 }
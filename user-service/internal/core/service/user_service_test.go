@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"user-service/internal/core/domain/entity"
+	"user-service/internal/core/domain/errs"
+	"user-service/utils/i18n"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCreateUserAccountPublishesVerificationEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   string
+		language string
+		wantLink func(token string) string
+	}{
+		{
+			name:     "web in the signup language",
+			client:   "",
+			language: "id",
+			wantLink: func(token string) string { return "https://sayur.id/verify?token=" + token + "&lang=id" },
+		},
+		{
+			name:     "android deep link",
+			client:   "android",
+			language: "en",
+			wantLink: func(token string) string { return "sayur://verify/" + token },
+		},
+		{
+			name:     "ios falls back to web",
+			client:   "ios",
+			language: "en",
+			wantLink: func(token string) string { return "https://sayur.id/verify?token=" + token + "&lang=en" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUserServiceFixture(t)
+
+			err := f.service.CreateUserAccount(context.Background(), entity.UserEntity{
+				Name:     "Siti Rahma",
+				Email:    "siti@example.com",
+				Password: "kebun-tomat-segar",
+				Language: tt.language,
+				Client:   tt.client,
+			})
+			if err != nil {
+				t.Fatalf("CreateUserAccount: %v", err)
+			}
+
+			user, err := f.repo.GetUserByEmail(context.Background(), "siti@example.com")
+			if err != nil {
+				t.Fatalf("user not stored: %v", err)
+			}
+			if user.Password == "kebun-tomat-segar" {
+				t.Error("password stored in the clear")
+			}
+			tokens := f.tokens.issued(user.ID, entity.TokenTypeEmailVerification)
+			if len(tokens) != 1 {
+				t.Fatalf("got %d verification tokens, want 1", len(tokens))
+			}
+
+			messages := f.publisher.Messages()
+			if len(messages) != 1 {
+				t.Fatalf("got %d notifications, want 1", len(messages))
+			}
+			want := entity.NotificationEntity{
+				Email:            "siti@example.com",
+				Message:          i18n.T(tt.language, "notification.user_verification", tt.wantLink(tokens[0])),
+				NotificationType: "user_verification",
+			}
+			if messages[0] != want {
+				t.Errorf("notification = %+v, want %+v", messages[0], want)
+			}
+		})
+	}
+}
+
+func TestCreateUserAccountRejectsWeakPassword(t *testing.T) {
+	f := newUserServiceFixture(t)
+
+	err := f.service.CreateUserAccount(context.Background(), entity.UserEntity{
+		Name:     "Siti Rahma",
+		Email:    "siti@example.com",
+		Password: "password",
+	})
+	if !errors.Is(err, errs.ErrWeakPassword) {
+		t.Fatalf("err = %v, want %v", err, errs.ErrWeakPassword)
+	}
+	if _, err := f.repo.GetUserByEmail(context.Background(), "siti@example.com"); !errors.Is(err, errs.ErrUserNotFound) {
+		t.Error("user stored despite a weak password")
+	}
+	if n := len(f.publisher.Messages()); n != 0 {
+		t.Errorf("got %d notifications, want none", n)
+	}
+}
+
+func TestForgotPasswordPublishesResetEmail(t *testing.T) {
+	f := newUserServiceFixture(t)
+	user := f.addUser(t, entity.UserEntity{
+		Name:       "Budi Santoso",
+		Email:      "budi@example.com",
+		Language:   "id",
+		IsVerified: true,
+	}, "kebun-tomat-segar")
+
+	err := f.service.ForgotPassword(context.Background(), entity.UserEntity{Email: "budi@example.com"})
+	if err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+
+	tokens := f.tokens.issued(user.ID, entity.TokenTypeResetPassword)
+	if len(tokens) != 1 {
+		t.Fatalf("got %d reset tokens, want 1", len(tokens))
+	}
+	messages := f.publisher.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(messages))
+	}
+	want := entity.NotificationEntity{
+		Email:            "budi@example.com",
+		Message:          i18n.T("id", "notification.reset_password", "https://sayur.id/reset?token="+tokens[0]),
+		NotificationType: "reset_password",
+	}
+	if messages[0] != want {
+		t.Errorf("notification = %+v, want %+v", messages[0], want)
+	}
+}
+
+func TestForgotPasswordAnswersEveryEmailAlike(t *testing.T) {
+	f := newUserServiceFixture(t)
+	f.addUser(t, entity.UserEntity{Name: "Budi Santoso", Email: "budi@example.com"}, "kebun-tomat-segar")
+	verified := f.addUser(t, entity.UserEntity{Name: "Siti Rahma", Email: "siti@example.com", IsVerified: true}, "kebun-tomat-segar")
+
+	for _, email := range []string{"budi@example.com", "nobody@example.com", "siti@example.com"} {
+		if err := f.service.ForgotPassword(context.Background(), entity.UserEntity{Email: email}); err != nil {
+			t.Errorf("%s: first request = %v, want nil", email, err)
+		}
+	}
+	// the throttle keys on the normalized email
+	for _, email := range []string{"budi@example.com", "nobody@example.com", " SITI@example.com"} {
+		err := f.service.ForgotPassword(context.Background(), entity.UserEntity{Email: email})
+		if !errors.Is(err, errs.ErrTooManyRequests) {
+			t.Errorf("%s: second request = %v, want %v", email, err, errs.ErrTooManyRequests)
+		}
+	}
+
+	if n := len(f.tokens.issued(verified.ID, entity.TokenTypeResetPassword)); n != 1 {
+		t.Errorf("got %d reset tokens, want 1", n)
+	}
+	messages := f.publisher.Messages()
+	if len(messages) != 1 || messages[0].Email != "siti@example.com" {
+		t.Errorf("notifications = %+v, want one to siti@example.com", messages)
+	}
+}
+
+func TestResendVerificationThrottlesEveryEmailAlike(t *testing.T) {
+	f := newUserServiceFixture(t)
+	pending := f.addUser(t, entity.UserEntity{Name: "Siti Rahma", Email: "siti@example.com"}, "kebun-tomat-segar")
+	f.addUser(t, entity.UserEntity{Name: "Budi Santoso", Email: "budi@example.com", IsVerified: true}, "kebun-tomat-segar")
+
+	for _, email := range []string{"siti@example.com", "budi@example.com", "nobody@example.com"} {
+		if err := f.service.ResendVerification(context.Background(), entity.UserEntity{Email: email}); err != nil {
+			t.Errorf("%s: first resend = %v, want nil", email, err)
+		}
+	}
+	// the throttle keys on the normalized email
+	for _, email := range []string{" SITI@example.com", "budi@example.com", "nobody@example.com"} {
+		err := f.service.ResendVerification(context.Background(), entity.UserEntity{Email: email})
+		if !errors.Is(err, errs.ErrTooManyRequests) {
+			t.Errorf("%s: second resend = %v, want %v", email, err, errs.ErrTooManyRequests)
+		}
+	}
+
+	if n := len(f.tokens.issued(pending.ID, entity.TokenTypeEmailVerification)); n != 1 {
+		t.Errorf("got %d verification tokens, want 1", n)
+	}
+	messages := f.publisher.Messages()
+	if len(messages) != 1 || messages[0].Email != "siti@example.com" {
+		t.Errorf("notifications = %+v, want one to siti@example.com", messages)
+	}
+}
+
+// txTracker is a unit of work that records whether a transaction is open.
+type txTracker struct {
+	open bool
+}
+
+func (w *txTracker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	w.open = true
+	defer func() { w.open = false }()
+	return fn(ctx)
+}
+
+// txCheckingBreachProvider fails the test when asked while a transaction is
+// open.
+type txCheckingBreachProvider struct {
+	t  *testing.T
+	tx *txTracker
+}
+
+func (p txCheckingBreachProvider) Range(ctx context.Context, prefix string) ([]string, error) {
+	if p.tx.open {
+		p.t.Error("breached password lookup ran inside the transaction")
+	}
+	return nil, nil
+}
+
+func TestUpdatePasswordValidatesBeforeTheTransaction(t *testing.T) {
+	f := newUserServiceFixture(t)
+	tx := &txTracker{}
+	passwords, err := NewPasswordService(testLogger(), f.cfg, txCheckingBreachProvider{t: t, tx: tx})
+	if err != nil {
+		t.Fatalf("NewPasswordService: %v", err)
+	}
+	f.service.passwords = passwords
+	f.service.unitOfWork = tx
+
+	user := f.addUser(t, entity.UserEntity{Name: "Budi Santoso", Email: "budi@example.com", IsVerified: true}, "kebun-tomat-segar")
+	if err := f.service.ForgotPassword(context.Background(), entity.UserEntity{Email: "budi@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	token := f.tokens.issued(user.ID, entity.TokenTypeResetPassword)[0]
+
+	// a password naming its owner is rejected and the token stays usable
+	err = f.service.UpdatePassword(context.Background(), entity.UserEntity{Token: token, Password: "budi-santoso-77"})
+	if !errors.Is(err, errs.ErrWeakPassword) {
+		t.Fatalf("weak password: err = %v, want %v", err, errs.ErrWeakPassword)
+	}
+	if f.repo.get(user.ID).Password != user.Password {
+		t.Fatal("password changed despite the rejection")
+	}
+
+	if err := f.service.UpdatePassword(context.Background(), entity.UserEntity{Token: token, Password: "sawah-padi-hijau"}); err != nil {
+		t.Fatalf("UpdatePassword: %v", err)
+	}
+	if ok, _, _ := passwords.Verify("sawah-padi-hijau", f.repo.get(user.ID).Password); !ok {
+		t.Error("new password not stored")
+	}
+
+	err = f.service.UpdatePassword(context.Background(), entity.UserEntity{Token: token, Password: "ladang-jagung-kuning"})
+	if !errors.Is(err, errs.ErrTokenInvalid) {
+		t.Errorf("reused token: err = %v, want %v", err, errs.ErrTokenInvalid)
+	}
+}
+
+func TestSignInRehashesLegacyPassword(t *testing.T) {
+	f := newUserServiceFixture(t)
+	legacy, err := bcrypt.GenerateFromPassword([]byte("kebun-tomat-segar"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	user := f.repo.add(entity.UserEntity{
+		Name:       "Budi Santoso",
+		Email:      "budi@example.com",
+		Password:   string(legacy),
+		IsVerified: true,
+	})
+
+	if _, _, err := f.service.SignIn(context.Background(), entity.UserEntity{Email: "budi@example.com", Password: "kebun-tomat-segar"}); err != nil {
+		t.Fatalf("SignIn: %v", err)
+	}
+
+	stored := f.repo.get(user.ID).Password
+	if !strings.HasPrefix(stored, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("stored hash = %q, want it rehashed with argon2id", stored)
+	}
+	if ok, rehash, err := f.passwords.Verify("kebun-tomat-segar", stored); err != nil || !ok || rehash {
+		t.Errorf("Verify(rehashed) = %v, %v, %v, want true, false, nil", ok, rehash, err)
+	}
+
+	// the rehashed password keeps working and is left alone
+	if _, _, err := f.service.SignIn(context.Background(), entity.UserEntity{Email: "budi@example.com", Password: "kebun-tomat-segar"}); err != nil {
+		t.Fatalf("second SignIn: %v", err)
+	}
+	if f.repo.get(user.ID).Password != stored {
+		t.Error("current hash replaced on the second sign in")
+	}
+}
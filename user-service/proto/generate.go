@@ -0,0 +1,5 @@
+// Package proto holds the protobuf definitions of the internal gRPC API and
+// the Go code generated from them.
+package proto
+
+//go:generate protoc -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative user/v1/user.proto
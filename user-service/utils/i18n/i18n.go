@@ -0,0 +1,93 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	English    = "en"
+	Indonesian = "id"
+)
+
+// Supported lists the languages with a full message catalog. The first one
+// is the fallback when a key is missing in another language.
+var Supported = []string{English, Indonesian}
+
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Indonesian})
+
+type languageKey struct{}
+
+// WithLanguage stores the negotiated language in ctx.
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// Language returns the language stored in ctx, or English when there is
+// none. Requests always carry one, see Middleware.
+func Language(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok && lang != "" {
+		return lang
+	}
+	return English
+}
+
+// IsSupported reports whether lang has a message catalog.
+func IsSupported(lang string) bool {
+	_, ok := catalog[lang]
+	return ok
+}
+
+// Normalize maps a stored or client supplied language to a supported one,
+// returning fallback when it cannot.
+func Normalize(lang, fallback string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if IsSupported(lang) {
+		return lang
+	}
+	return Negotiate(lang, fallback)
+}
+
+// Negotiate picks the best supported language for an Accept-Language header.
+func Negotiate(acceptLanguage, fallback string) string {
+	if acceptLanguage == "" {
+		return fallback
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return fallback
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return fallback
+	}
+	return Supported[index]
+}
+
+// T returns the message for key in lang formatted with args. Missing keys
+// fall back to English, then to the key itself.
+func T(lang, key string, args ...any) string {
+	message, ok := catalog[lang][key]
+	if !ok {
+		message, ok = catalog[English][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Has reports whether key has a message in any language.
+func Has(key string) bool {
+	_, ok := catalog[English][key]
+	return ok
+}
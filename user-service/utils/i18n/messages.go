@@ -0,0 +1,87 @@
+package i18n
+
+// catalog holds every user facing message. Error messages are keyed by
+// "error." plus the domain error code, password policy messages by
+// "password." plus the rule.
+var catalog = map[string]map[string]string{
+	English: {
+		"success":           "Success",
+		"password_updated":  "Password updated successfully",
+		"verification_sent": "If the account exists and is not verified yet, a new verification email is on its way",
+
+		"error.internal_error":           "internal server error",
+		"error.bad_request":              "bad request",
+		"error.validation_failed":        "request validation failed",
+		"error.not_found":                "resource not found",
+		"error.unauthorized":             "unauthorized",
+		"error.forbidden":                "forbidden",
+		"error.conflict":                 "conflict",
+		"error.service_unavailable":      "service unavailable",
+		"error.user_not_found":           "user not found",
+		"error.email_already_registered": "email is already registered",
+		"error.invalid_credentials":      "email or password is incorrect",
+		"error.token_missing":            "missing or invalid token",
+		"error.token_invalid":            "token is invalid",
+		"error.token_expired":            "token has expired",
+		"error.session_not_found":        "session not found",
+		"error.account_suspended":        "account is suspended",
+		"error.role_not_found":           "role not found",
+		"error.account_not_verified":     "account is not verified, check your email for the verification link",
+		"error.too_many_requests":        "too many requests, try again later",
+		"error.weak_password":            "password does not meet the password policy",
+
+		"password.min_length": "must be at least %s characters",
+		"password.max_length": "must be at most %s characters",
+		"password.upper":      "must contain an upper case letter",
+		"password.lower":      "must contain a lower case letter",
+		"password.digit":      "must contain a digit",
+		"password.symbol":     "must contain a symbol",
+		"password.common":     "is too common",
+		"password.personal":   "must not contain your name or email",
+		"password.breached":   "has appeared in a data breach, choose another one",
+
+		"notification.user_verification":     "Please verify your account with click link below: %s",
+		"notification.reset_password":        "Please click link below for reset password: %s",
+		"notification.verification_reminder": "You have not verified your account yet. Please verify it with the link below: %s",
+	},
+	Indonesian: {
+		"success":           "Berhasil",
+		"password_updated":  "Password berhasil diperbarui",
+		"verification_sent": "Jika akun terdaftar dan belum diverifikasi, email verifikasi baru sedang dikirim",
+
+		"error.internal_error":           "terjadi kesalahan pada server",
+		"error.bad_request":              "permintaan tidak valid",
+		"error.validation_failed":        "validasi permintaan gagal",
+		"error.not_found":                "data tidak ditemukan",
+		"error.unauthorized":             "tidak memiliki akses",
+		"error.forbidden":                "akses ditolak",
+		"error.conflict":                 "data bentrok dengan data yang sudah ada",
+		"error.service_unavailable":      "layanan sedang tidak tersedia",
+		"error.user_not_found":           "pengguna tidak ditemukan",
+		"error.email_already_registered": "email sudah terdaftar",
+		"error.invalid_credentials":      "email atau password salah",
+		"error.token_missing":            "token tidak ada atau tidak valid",
+		"error.token_invalid":            "token tidak valid",
+		"error.token_expired":            "token sudah kedaluwarsa",
+		"error.session_not_found":        "sesi tidak ditemukan",
+		"error.account_suspended":        "akun sedang ditangguhkan",
+		"error.role_not_found":           "role tidak ditemukan",
+		"error.account_not_verified":     "akun belum diverifikasi, cek email Anda untuk link verifikasi",
+		"error.too_many_requests":        "terlalu banyak permintaan, coba lagi nanti",
+		"error.weak_password":            "password tidak memenuhi kebijakan password",
+
+		"password.min_length": "minimal %s karakter",
+		"password.max_length": "maksimal %s karakter",
+		"password.upper":      "harus mengandung huruf besar",
+		"password.lower":      "harus mengandung huruf kecil",
+		"password.digit":      "harus mengandung angka",
+		"password.symbol":     "harus mengandung simbol",
+		"password.common":     "terlalu umum",
+		"password.personal":   "tidak boleh mengandung nama atau email Anda",
+		"password.breached":   "pernah bocor dalam insiden kebocoran data, pilih password lain",
+
+		"notification.user_verification":     "Silakan verifikasi akun Anda dengan klik tautan berikut: %s",
+		"notification.reset_password":        "Silakan klik tautan berikut untuk mengatur ulang password: %s",
+		"notification.verification_reminder": "Akun Anda belum diverifikasi. Silakan verifikasi dengan klik tautan berikut: %s",
+	},
+}
@@ -0,0 +1,19 @@
+package i18n
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware negotiates the response language from Accept-Language, stores
+// it in the request context and reports it in Content-Language.
+func Middleware(fallback string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			lang := Negotiate(c.Request().Header.Get("Accept-Language"), fallback)
+			ctx := WithLanguage(c.Request().Context(), lang)
+			c.SetRequest(c.Request().WithContext(ctx))
+			c.Response().Header().Set("Content-Language", lang)
+			return next(c)
+		}
+	}
+}
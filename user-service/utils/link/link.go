@@ -0,0 +1,83 @@
+// Package link builds the links mailed to users from URL templates, one per
+// link type and client app.
+package link
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Type is the purpose of a link.
+type Type string
+
+const (
+	VerifyEmail   Type = "verify_email"
+	ResetPassword Type = "reset_password"
+)
+
+// Clients a link can be built for. Web is the default and the fallback for
+// clients without a template of their own.
+const (
+	Web     = "web"
+	Android = "android"
+	IOS     = "ios"
+)
+
+var Clients = []string{Web, Android, IOS}
+
+// Template placeholders. Both are query escaped when filled in.
+const (
+	tokenPlaceholder = "{token}"
+	langPlaceholder  = "{lang}"
+)
+
+// Templates holds the URL templates by link type and client.
+type Templates map[Type]map[string]string
+
+type Builder struct {
+	templates Templates
+}
+
+// Build returns the link of linkType for client carrying token. An empty or
+// unknown client gets the web link, as does a client without a template.
+func (b *Builder) Build(linkType Type, client, token, lang string) (string, error) {
+	templates, ok := b.templates[linkType]
+	if !ok {
+		return "", fmt.Errorf("unknown link type %q", linkType)
+	}
+
+	template := templates[client]
+	if template == "" {
+		template = templates[Web]
+	}
+	if template == "" {
+		return "", fmt.Errorf("no template for %s links", linkType)
+	}
+
+	return strings.NewReplacer(
+		tokenPlaceholder, url.QueryEscape(token),
+		langPlaceholder, url.QueryEscape(lang),
+	).Replace(template), nil
+}
+
+// Check reports whether template is an absolute URL, such as
+// https://sayur.id/verify?token={token} or sayur://verify/{token}, that
+// carries the token.
+func Check(template string) error {
+	if !strings.Contains(template, tokenPlaceholder) {
+		return fmt.Errorf("must contain %s", tokenPlaceholder)
+	}
+	u, err := url.Parse(template)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("must be an absolute URL")
+	}
+	return nil
+}
+
+func NewBuilder(templates Templates) *Builder {
+	return &Builder{templates: templates}
+}
@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Options configures New.
+type Options struct {
+	Level  string
+	Format string
+	Output io.Writer
+}
+
+// New returns the service wide structured logger. Records are enriched with
+// the request id, user id and trace id found in the context, and sensitive
+// attributes are redacted before they are written.
+func New(opts Options) *slog.Logger {
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		Level:       parseLevel(opts.Level),
+		ReplaceAttr: redact,
+	}
+
+	var handler slog.Handler
+	if opts.Format == FormatJSON {
+		handler = slog.NewJSONHandler(opts.Output, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(opts.Output, handlerOpts)
+	}
+
+	return slog.New(contextHandler{Handler: handler})
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+type requestFieldsKey struct{}
+
+// requestFields is shared by every layer handling a request. It is a pointer
+// so fields learned late, like the user id set by the auth middleware, are
+// visible to the request log written by an outer middleware.
+type requestFields struct {
+	mu        sync.RWMutex
+	requestID string
+	userID    int64
+}
+
+// WithRequestID returns a context carrying the request id.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestFieldsKey{}, &requestFields{requestID: requestID})
+}
+
+// RequestID returns the request id carried by ctx, or "".
+func RequestID(ctx context.Context) string {
+	fields, ok := ctx.Value(requestFieldsKey{}).(*requestFields)
+	if !ok {
+		return ""
+	}
+
+	fields.mu.RLock()
+	defer fields.mu.RUnlock()
+	return fields.requestID
+}
+
+// SetUserID records the authenticated user on the request carried by ctx.
+func SetUserID(ctx context.Context, userID int64) {
+	fields, ok := ctx.Value(requestFieldsKey{}).(*requestFields)
+	if !ok {
+		return
+	}
+
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	fields.userID = userID
+}
+
+// UserID returns the authenticated user id of the request, or 0.
+func UserID(ctx context.Context) int64 {
+	fields, ok := ctx.Value(requestFieldsKey{}).(*requestFields)
+	if !ok {
+		return 0
+	}
+
+	fields.mu.RLock()
+	defer fields.mu.RUnlock()
+	return fields.userID
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if userID := UserID(ctx); userID != 0 {
+		record.AddAttrs(slog.Int64("user_id", userID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are never written.
+var sensitiveKeys = map[string]bool{
+	"password":              true,
+	"password_new":          true,
+	"password_confirmation": true,
+	"token":                 true,
+	"access_token":          true,
+	"refresh_token":         true,
+	"authorization":         true,
+	"secret":                true,
+	"jwt_secret_key":        true,
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+
+	switch {
+	case sensitiveKeys[key]:
+		return slog.String(attr.Key, redacted)
+	case key == "email":
+		return slog.String(attr.Key, MaskEmail(attr.Value.String()))
+	}
+	return attr
+}
+
+// MaskEmail keeps just enough of an address to correlate log lines:
+// "fredy.bambang@gmail.com" becomes "f***@gmail.com".
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		if email == "" {
+			return ""
+		}
+		return redacted
+	}
+	return email[:1] + "***" + email[at:]
+}
@@ -0,0 +1,155 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnknownHash is returned for a stored hash no scheme recognizes.
+var ErrUnknownHash = errors.New("password hash format not recognized")
+
+// Scheme hashes passwords in one format and verifies hashes in it.
+type Scheme interface {
+	Hash(password string) (string, error)
+	// Verify reports whether password matches hash. stale is set when hash
+	// was made with other parameters than the scheme's own.
+	Verify(password, hash string) (ok, stale bool, err error)
+	// Recognizes reports whether hash is in the scheme's format.
+	Recognizes(hash string) bool
+}
+
+// Hasher hashes new passwords with its current scheme and verifies hashes of
+// every scheme it knows, so users hashed with an older one can still sign in.
+type Hasher struct {
+	current Scheme
+	schemes []Scheme
+}
+
+// Hash hashes password with the current scheme.
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify reports whether password matches hash, and whether hash should be
+// replaced with a fresh Hash because it was made with a legacy scheme or
+// outdated parameters.
+func (h *Hasher) Verify(password, hash string) (ok, rehash bool, err error) {
+	for _, scheme := range h.schemes {
+		if !scheme.Recognizes(hash) {
+			continue
+		}
+		ok, stale, err := scheme.Verify(password, hash)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, stale || scheme != h.current, nil
+	}
+	return false, false, ErrUnknownHash
+}
+
+// NewHasher returns a hasher hashing with current and verifying hashes of
+// current and legacy.
+func NewHasher(current Scheme, legacy ...Scheme) *Hasher {
+	return &Hasher{current: current, schemes: append([]Scheme{current}, legacy...)}
+}
+
+const (
+	argon2idPrefix = "$argon2id$"
+	argon2SaltLen  = 16
+	argon2KeyLen   = 32
+)
+
+// Argon2id hashes into PHC strings such as
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>. Memory is in KiB.
+type Argon2id struct {
+	Memory      uint32
+	Time        uint32
+	Parallelism uint8
+}
+
+func (a Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Time, a.Memory, a.Parallelism, argon2KeyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version, a.Memory, a.Time, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a Argon2id) Verify(password, hash string) (bool, bool, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false, fmt.Errorf("argon2id hash: expected 6 fields, got %d", len(parts))
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, false, fmt.Errorf("argon2id hash version: %w", err)
+	}
+	if version != argon2.Version {
+		return false, false, fmt.Errorf("argon2id hash: unsupported version %d", version)
+	}
+
+	var params Argon2id
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Parallelism); err != nil {
+		return false, false, fmt.Errorf("argon2id hash parameters: %w", err)
+	}
+	// argon2 panics on zero parallelism and misbehaves on zero memory or time
+	if params.Memory == 0 || params.Time == 0 || params.Parallelism == 0 {
+		return false, false, fmt.Errorf("argon2id hash parameters: m, t and p must be positive, got %q", parts[3])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, fmt.Errorf("argon2id hash salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, fmt.Errorf("argon2id hash key: %w", err)
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, false, nil
+	}
+	return true, params != a || len(key) != argon2KeyLen, nil
+}
+
+func (a Argon2id) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, argon2idPrefix)
+}
+
+// Bcrypt handles $2a$, $2b$ and $2y$ hashes. It is kept to verify hashes
+// made before Argon2id; passwords longer than 72 bytes cannot be hashed.
+type Bcrypt struct {
+	Cost int
+}
+
+func (b Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	return string(hash), err
+}
+
+func (b Bcrypt) Verify(password, hash string) (bool, bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return true, err != nil || cost != b.Cost, nil
+}
+
+func (b Bcrypt) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -0,0 +1,124 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2id keeps the tests fast; production parameters are far higher.
+var testArgon2id = Argon2id{Memory: 64, Time: 1, Parallelism: 1}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	hash, err := testArgon2id.Hash("kebun-tomat-segar")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") || strings.Count(hash, "$") != 5 {
+		t.Fatalf("hash = %q, want a PHC string with the scheme parameters", hash)
+	}
+	if !testArgon2id.Recognizes(hash) {
+		t.Error("Recognizes rejected its own hash")
+	}
+
+	other, err := testArgon2id.Hash("kebun-tomat-segar")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if other == hash {
+		t.Error("two hashes of one password are equal; the salt is not random")
+	}
+
+	ok, stale, err := testArgon2id.Verify("kebun-tomat-segar", hash)
+	if err != nil || !ok || stale {
+		t.Errorf("Verify(right password) = %v, %v, %v, want true, false, nil", ok, stale, err)
+	}
+	ok, _, err = testArgon2id.Verify("kebun-tomat-busuk", hash)
+	if err != nil || ok {
+		t.Errorf("Verify(wrong password) = %v, %v, want false, nil", ok, err)
+	}
+}
+
+func TestArgon2idStaleParameters(t *testing.T) {
+	hash, err := Argon2id{Memory: 32, Time: 1, Parallelism: 1}.Hash("kebun-tomat-segar")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	ok, stale, err := testArgon2id.Verify("kebun-tomat-segar", hash)
+	if err != nil || !ok || !stale {
+		t.Errorf("Verify = %v, %v, %v, want true, true, nil", ok, stale, err)
+	}
+}
+
+func TestArgon2idRejectsMalformedHashes(t *testing.T) {
+	valid, err := testArgon2id.Hash("kebun-tomat-segar")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	fields := strings.Split(valid, "$")
+	salt, key := fields[4], fields[5]
+
+	tests := map[string]string{
+		"missing fields":   "$argon2id$v=19$m=64,t=1,p=1$" + salt,
+		"unknown version":  "$argon2id$v=16$m=64,t=1,p=1$" + salt + "$" + key,
+		"garbled params":   "$argon2id$v=19$m=lots$" + salt + "$" + key,
+		"zero memory":      "$argon2id$v=19$m=0,t=1,p=1$" + salt + "$" + key,
+		"zero time":        "$argon2id$v=19$m=64,t=0,p=1$" + salt + "$" + key,
+		"zero parallelism": "$argon2id$v=19$m=64,t=1,p=0$" + salt + "$" + key,
+		"salt not base64":  "$argon2id$v=19$m=64,t=1,p=1$%%%$" + key,
+		"key not base64":   "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$%%%",
+	}
+
+	for name, hash := range tests {
+		t.Run(name, func(t *testing.T) {
+			ok, _, err := testArgon2id.Verify("kebun-tomat-segar", hash)
+			if err == nil || ok {
+				t.Errorf("Verify(%q) = %v, %v, want an error", hash, ok, err)
+			}
+		})
+	}
+}
+
+func TestHasherRehashesLegacyBcrypt(t *testing.T) {
+	hasher := NewHasher(testArgon2id, Bcrypt{Cost: bcrypt.MinCost})
+
+	legacy, err := Bcrypt{Cost: bcrypt.MinCost}.Hash("kebun-tomat-segar")
+	if err != nil {
+		t.Fatalf("bcrypt Hash: %v", err)
+	}
+
+	ok, rehash, err := hasher.Verify("kebun-tomat-segar", legacy)
+	if err != nil || !ok || !rehash {
+		t.Fatalf("Verify(bcrypt) = %v, %v, %v, want true, true, nil", ok, rehash, err)
+	}
+	ok, rehash, err = hasher.Verify("kebun-tomat-busuk", legacy)
+	if err != nil || ok || rehash {
+		t.Errorf("Verify(bcrypt, wrong password) = %v, %v, %v, want false, false, nil", ok, rehash, err)
+	}
+
+	fresh, err := hasher.Hash("kebun-tomat-segar")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !testArgon2id.Recognizes(fresh) {
+		t.Errorf("Hash = %q, want an argon2id hash", fresh)
+	}
+	ok, rehash, err = hasher.Verify("kebun-tomat-segar", fresh)
+	if err != nil || !ok || rehash {
+		t.Errorf("Verify(argon2id) = %v, %v, %v, want true, false, nil", ok, rehash, err)
+	}
+}
+
+func TestHasherUnknownHash(t *testing.T) {
+	hasher := NewHasher(testArgon2id, Bcrypt{Cost: bcrypt.MinCost})
+
+	for _, hash := range []string{"", "kebun-tomat-segar", "$1$md5crypt$abcdefgh", "$scrypt$ln=15,r=8,p=1$c2FsdA$aGFzaA"} {
+		ok, rehash, err := hasher.Verify("kebun-tomat-segar", hash)
+		if !errors.Is(err, ErrUnknownHash) || ok || rehash {
+			t.Errorf("Verify(%q) = %v, %v, %v, want %v", hash, ok, rehash, err, ErrUnknownHash)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package password checks new passwords against the password policy and
+// hashes them.
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules a password can break. They double as i18n keys under "password.".
+const (
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RuleUpper     = "upper"
+	RuleLower     = "lower"
+	RuleDigit     = "digit"
+	RuleSymbol    = "symbol"
+	RuleCommon    = "common"
+	RulePersonal  = "personal"
+	RuleBreached  = "breached"
+)
+
+// personalMinLength is the shortest name part or email local part checked
+// for, so short names like "Al" do not rule out every password with "al".
+const personalMinLength = 3
+
+//go:embed common.txt
+var commonPasswords string
+
+// Violation is a rule a password breaks. Param is the rule argument, such as
+// the minimum length.
+type Violation struct {
+	Rule  string
+	Param string
+}
+
+// Policy is the set of rules a new password must meet.
+type Policy struct {
+	MinLength      int
+	MaxLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSymbol  bool
+	RejectPersonal bool
+
+	blocklist map[string]struct{}
+}
+
+// Check returns every rule password breaks. personal holds the name and
+// email of the owner, when known; the password must not contain them.
+func (p *Policy) Check(password string, personal ...string) []Violation {
+	var violations []Violation
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, Violation{Rule: RuleMinLength, Param: strconv.Itoa(p.MinLength)})
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, Violation{Rule: RuleMaxLength, Param: strconv.Itoa(p.MaxLength)})
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		violations = append(violations, Violation{Rule: RuleUpper})
+	}
+	if p.RequireLower && !lower {
+		violations = append(violations, Violation{Rule: RuleLower})
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, Violation{Rule: RuleDigit})
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, Violation{Rule: RuleSymbol})
+	}
+
+	folded := strings.ToLower(password)
+	if _, ok := p.blocklist[folded]; ok {
+		violations = append(violations, Violation{Rule: RuleCommon})
+	}
+	if p.RejectPersonal && containsPersonal(folded, personal) {
+		violations = append(violations, Violation{Rule: RulePersonal})
+	}
+
+	return violations
+}
+
+// containsPersonal reports whether password, lower cased, contains a name
+// part or the local part of an email.
+func containsPersonal(password string, personal []string) bool {
+	for _, value := range personal {
+		value = strings.ToLower(value)
+		if at := strings.LastIndex(value, "@"); at >= 0 {
+			value = value[:at]
+		}
+		parts := strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		// the whole local part too, so "john.doe" catches "johndoe"
+		parts = append(parts, strings.Join(parts, ""))
+		for _, part := range parts {
+			if utf8.RuneCountInString(part) >= personalMinLength && strings.Contains(password, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewPolicy returns policy with the built in common passwords blocked, plus
+// those in blocklistFile when it is set.
+func NewPolicy(policy Policy, blocklistFile string) (*Policy, error) {
+	policy.blocklist = map[string]struct{}{}
+	if err := readList(strings.NewReader(commonPasswords), policy.blocklist); err != nil {
+		return nil, err
+	}
+
+	if blocklistFile != "" {
+		f, err := os.Open(blocklistFile)
+		if err != nil {
+			return nil, fmt.Errorf("open password blocklist: %w", err)
+		}
+		defer f.Close()
+		if err := readList(f, policy.blocklist); err != nil {
+			return nil, fmt.Errorf("read password blocklist %s: %w", blocklistFile, err)
+		}
+	}
+	return &policy, nil
+}
+
+// readList adds the lines of r, lower cased, to list. Blank lines and lines
+// starting with # are skipped.
+func readList(r io.Reader, list map[string]struct{}) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list[strings.ToLower(line)] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// RangeKey splits the upper case hex SHA-1 hash of password into the five
+// character prefix sent to a breached password provider and the suffix
+// looked for in its answer.
+func RangeKey(password string) (prefix, suffix string) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return hash[:5], hash[5:]
+}
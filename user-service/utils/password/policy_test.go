@@ -0,0 +1,134 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	strict := Policy{
+		MinLength:      10,
+		MaxLength:      20,
+		RequireUpper:   true,
+		RequireLower:   true,
+		RequireDigit:   true,
+		RequireSymbol:  true,
+		RejectPersonal: true,
+	}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		password string
+		personal []string
+		want     []Violation
+	}{
+		{name: "meets every rule", policy: strict, password: "Kebun-Tomat-7", want: nil},
+		{name: "too short", policy: strict, password: "Kb-7a", want: []Violation{{Rule: RuleMinLength, Param: "10"}}},
+		{name: "length counts runes", policy: Policy{MinLength: 4}, password: "ñöüé", want: nil},
+		{name: "too long", policy: strict, password: "Kebun-Tomat-7-Segar-Sekali", want: []Violation{{Rule: RuleMaxLength, Param: "20"}}},
+		{name: "no maximum", policy: Policy{MinLength: 1}, password: "kebun-tomat-segar-sekali-dan-panjang-sekali", want: nil},
+		{name: "no upper", policy: strict, password: "kebun-tomat-7", want: []Violation{{Rule: RuleUpper}}},
+		{name: "no lower", policy: strict, password: "KEBUN-TOMAT-7", want: []Violation{{Rule: RuleLower}}},
+		{name: "no digit", policy: strict, password: "Kebun-Tomat-X", want: []Violation{{Rule: RuleDigit}}},
+		{name: "no symbol", policy: strict, password: "KebunTomat77", want: []Violation{{Rule: RuleSymbol}}},
+		{name: "space counts as symbol", policy: strict, password: "Kebun Tomat 7", want: nil},
+		{name: "common", policy: Policy{MinLength: 8}, password: "Password", want: []Violation{{Rule: RuleCommon}}},
+		{name: "personal", policy: strict, password: "Siti-Rahma-77", personal: []string{"Siti Rahma", "siti@example.com"}, want: []Violation{{Rule: RulePersonal}}},
+		{name: "personal not checked", policy: Policy{MinLength: 8}, password: "siti-rahma-77", personal: []string{"Siti Rahma"}, want: nil},
+		{
+			name:     "every broken rule at once",
+			policy:   strict,
+			password: "password",
+			want: []Violation{
+				{Rule: RuleMinLength, Param: "10"},
+				{Rule: RuleUpper},
+				{Rule: RuleDigit},
+				{Rule: RuleSymbol},
+				{Rule: RuleCommon},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewPolicy(tt.policy, "")
+			if err != nil {
+				t.Fatalf("NewPolicy: %v", err)
+			}
+			if got := policy.Check(tt.password, tt.personal...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPolicyBlocklistFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(file, []byte("# shop names\n\n  SayurSegar2024  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := NewPolicy(Policy{MinLength: 8}, file)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	for _, password := range []string{"sayursegar2024", "password"} {
+		if got := policy.Check(password); !reflect.DeepEqual(got, []Violation{{Rule: RuleCommon}}) {
+			t.Errorf("Check(%q) = %v, want it blocked", password, got)
+		}
+	}
+
+	if _, err := NewPolicy(Policy{}, filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("NewPolicy accepted a missing blocklist file")
+	}
+}
+
+func TestContainsPersonal(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		personal []string
+		want     bool
+	}{
+		{name: "first name", password: "i love budi 99", personal: []string{"Budi Santoso"}, want: true},
+		{name: "last name", password: "santoso-kebun", personal: []string{"Budi Santoso"}, want: true},
+		{name: "case folded", password: "xxsantosoxx", personal: []string{"BUDI SANTOSO"}, want: true},
+		{name: "email local part", password: "kebun-siti.rahma", personal: []string{"siti.rahma@example.com"}, want: true},
+		{name: "email local part joined", password: "sitirahma-kebun", personal: []string{"siti.rahma@example.com"}, want: true},
+		{name: "email domain is ignored", password: "example-kebun", personal: []string{"siti@example.com"}, want: false},
+		{name: "short parts are ignored", password: "al-kebun-tomat", personal: []string{"Al Bo"}, want: false},
+		{name: "unrelated", password: "kebun-tomat-segar", personal: []string{"Budi Santoso", "budi@example.com"}, want: false},
+		{name: "nothing known", password: "kebun-tomat-segar", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsPersonal(tt.password, tt.personal); got != tt.want {
+				t.Errorf("containsPersonal(%q, %q) = %v, want %v", tt.password, tt.personal, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRangeKey(t *testing.T) {
+	tests := []struct {
+		password string
+		prefix   string
+		suffix   string
+	}{
+		// SHA-1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+		{password: "password", prefix: "5BAA6", suffix: "1E4C9B93F3F0682250B6CF8331B7EE68FD8"},
+		// SHA-1("") = DA39A3EE5E6B4B0D3255BFEF95601890AFD80709
+		{password: "", prefix: "DA39A", suffix: "3EE5E6B4B0D3255BFEF95601890AFD80709"},
+	}
+
+	for _, tt := range tests {
+		prefix, suffix := RangeKey(tt.password)
+		if prefix != tt.prefix || suffix != tt.suffix {
+			t.Errorf("RangeKey(%q) = %s, %s, want %s, %s", tt.password, prefix, suffix, tt.prefix, tt.suffix)
+		}
+	}
+}
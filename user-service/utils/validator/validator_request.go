@@ -1,46 +1,138 @@
 package validator
 
 import (
-	"errors"
+	"reflect"
+	"strings"
+	"user-service/utils/i18n"
 
 	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
 	"github.com/go-playground/validator/v10"
-	"github.com/labstack/gommon/log"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
 
 	ut "github.com/go-playground/universal-translator"
 )
 
 type Validator struct {
-	Validator  *validator.Validate
-	Translator ut.Translator
+	Validator   *validator.Validate
+	Translators map[string]ut.Translator
 }
 
-func NewValidator() *Validator {
-	en := en.New()
-	uni := ut.New(en, en)
-	trans, found := uni.GetTranslator("en")
-	if !found {
-		log.Fatalf("[NewValidator-1] Translator not found")
+// FieldError describes one failed rule on one request field. Messages holds
+// the message in every supported language.
+type FieldError struct {
+	Field    string
+	Rule     string
+	Param    string
+	Messages map[string]string
+}
+
+// Message returns the field message in lang, falling back to English.
+func (f FieldError) Message(lang string) string {
+	if message, ok := f.Messages[lang]; ok {
+		return message
 	}
+	return f.Messages[i18n.English]
+}
+
+// ValidationError lists every failed rule of a request, not just the first.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message(i18n.English))
+	}
+	return strings.Join(messages, "; ")
+}
+
+func NewValidator() *Validator {
+	enLocale, idLocale := en.New(), id.New()
+	uni := ut.New(enLocale, enLocale, idLocale)
 
 	validate := validator.New()
+	// report fields by their json name, which is what clients send
+	validate.RegisterTagNameFunc(jsonName)
+
+	register := map[string]func(*validator.Validate, ut.Translator) error{
+		i18n.English:    en_translations.RegisterDefaultTranslations,
+		i18n.Indonesian: id_translations.RegisterDefaultTranslations,
+	}
+	translators := make(map[string]ut.Translator, len(register))
+	for lang, registerTranslations := range register {
+		trans, found := uni.GetTranslator(lang)
+		if !found {
+			panic("validator: translator " + lang + " not found")
+		}
+		if err := registerTranslations(validate, trans); err != nil {
+			panic("validator: register " + lang + " translations: " + err.Error())
+		}
+		translators[lang] = trans
+	}
 
 	return &Validator{
-		Validator:  validate,
-		Translator: trans,
+		Validator:   validate,
+		Translators: translators,
 	}
 }
 
+// Validate implements echo.Validator. Failed rules are returned together as
+// a *ValidationError.
 func (v *Validator) Validate(i interface{}) error {
 	err := v.Validator.Struct(i)
-	if err != nil {
-		object, _ := err.(validator.ValidationErrors)
-		for _, e := range object {
-			log.Infof("[Validate-1] %s: %s", e.Field(), e.Translate(v.Translator))
+	if err == nil {
+		return nil
+	}
 
-			return errors.New(e.Translate(v.Translator))
+	object, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	structType := reflect.Indirect(reflect.ValueOf(i)).Type()
+	fields := make([]FieldError, 0, len(object))
+	for _, e := range object {
+		param := e.Param()
+		// cross-field rules such as eqfield name a struct field
+		if field, ok := structType.FieldByName(param); ok && strings.HasSuffix(e.Tag(), "field") {
+			param = jsonName(field)
 		}
+		fields = append(fields, FieldError{
+			Field:    e.Field(),
+			Rule:     e.Tag(),
+			Param:    param,
+			Messages: v.translate(e, param),
+		})
 	}
+	return &ValidationError{Fields: fields}
+}
 
-	return nil
+// translate renders e in every language. Cross-field rules are rendered with
+// the json name of the other field instead of its Go name.
+func (v *Validator) translate(e validator.FieldError, param string) map[string]string {
+	messages := make(map[string]string, len(v.Translators))
+	for lang, trans := range v.Translators {
+		message := e.Translate(trans)
+		if param != e.Param() {
+			if translated, err := trans.T(e.Tag(), e.Field(), param); err == nil {
+				message = translated
+			}
+		}
+		messages[lang] = message
+	}
+	return messages
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
 }